@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/kurtiz/commit-feed/internals/cache"
+)
+
+var (
+	pruneMaxSize string
+	pruneMaxAge  time.Duration
+)
+
+// cacheCmd groups the response cache subcommands
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of generated posts.",
+	Long: `CommitFeed caches generated posts under ~/.commit-feed/cache, keyed by the commits,
+platforms, project context, provider, model and prompt version. Running generate again
+on the same input returns the cached posts instantly instead of calling the AI provider.`,
+}
+
+// cachePruneCmd removes old entries and keeps the cache under a size limit
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove old cache entries and keep the cache under a size limit.",
+	Long: `Remove cache entries older than --max-age, then remove the oldest remaining
+entries until the cache is no larger than --max-size.
+
+Examples:
+  # Use the default limits (100MB, 30 days)
+  commitfeed cache prune
+
+  # Keep at most 10MB of entries from the last week
+  commitfeed cache prune --max-size 10MB --max-age 168h`,
+	Run: func(cmd *cobra.Command, args []string) {
+		maxSize, err := humanize.ParseBytes(pruneMaxSize)
+		if err != nil {
+			fmt.Printf("❌ Invalid --max-size %q: %v\n", pruneMaxSize, err)
+			os.Exit(1)
+		}
+
+		result, err := cache.Prune(int64(maxSize), pruneMaxAge)
+		if err != nil {
+			fmt.Println("❌ Failed to prune cache:", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("🧹 Removed %d entries (%s freed). %d entries remain (%s).\n",
+			result.Removed, humanize.Bytes(uint64(result.Freed)), result.Remaining, humanize.Bytes(uint64(result.Size)))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+
+	cachePruneCmd.Flags().StringVar(&pruneMaxSize, "max-size", "100MB", "Maximum total cache size (0 for no limit)")
+	cachePruneCmd.Flags().DurationVar(&pruneMaxAge, "max-age", 30*24*time.Hour, "Remove entries older than this (0 for no limit)")
+}
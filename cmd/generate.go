@@ -5,12 +5,21 @@ Copyright © 2025 Aaron Will Djaba <aaronwilldjaba@outlook.com>
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/kurtiz/commit-feed/internals/ai"
+	"github.com/kurtiz/commit-feed/internals/cache"
 	"github.com/kurtiz/commit-feed/internals/config"
 	"github.com/kurtiz/commit-feed/internals/git"
 )
@@ -19,6 +28,27 @@ var (
 	rangeFlag     string
 	platformsFlag []string
 	postFlag      bool // if true, actually post
+	modelFlag     string
+	tempFlag      float32
+	timeoutFlag   time.Duration
+	streamFlag    bool
+	noCacheFlag   bool
+	refreshFlag   bool
+	showPrompt    bool
+	variantsFlag  int
+	shortenFlag   int
+	threadFlag    bool
+	voiceFlag     string
+	langFlag      []string
+	verifyFlag    bool
+	forceFlag     bool
+	providerFlag  string
+	fixturesFlag  string
+	recordFlag    bool
+	fakeFlag      string
+	perPlatform   bool
+	concurrency   int
+	debugFlag     bool
 )
 
 // generateCmd represents the generate command
@@ -42,7 +72,25 @@ Examples:
   commitfeed generate --post
 
   # Generate and post only to Twitter
-  commitfeed generate --platforms=twitter --post`,
+  commitfeed generate --platforms=twitter --post
+
+  # Generate three candidates per platform and pick one
+  commitfeed generate --variants 3
+
+  # Write in the voice of your team's earlier posts
+  commitfeed generate --voice team
+
+  # Generate a numbered thread for a big release
+  commitfeed generate --range v1.0..v2.0 --platforms=x,bluesky --thread
+
+  # Generate every post in French and in English
+  commitfeed generate --lang fr,en
+
+  # Flag claims the commits do not support before posting
+  commitfeed generate --verify --post
+
+  # Write each platform's post with a focused request of its own, in parallel
+  commitfeed generate --platforms linkedin,x,mastodon --per-platform`,
 
 	Run: func(cmd *cobra.Command, args []string) {
 		// --- 1️⃣ Check Git prerequisites ---
@@ -67,8 +115,53 @@ Examples:
 		if len(platformsFlag) > 0 {
 			targetPlatforms = platformsFlag
 		}
+		targetPlatforms = ai.NormalizePlatforms(targetPlatforms)
+
+		if variantsFlag < 1 || variantsFlag > ai.MaxVariants {
+			fmt.Printf("❌ --variants must be between 1 and %d.\n", ai.MaxVariants)
+			return
+		}
+		if recordFlag && fixturesFlag == "" {
+			fmt.Println("❌ --record needs --fixtures, the directory to record into.")
+			return
+		}
+		if variantsFlag > 1 && threadFlag {
+			fmt.Println("❌ --variants cannot be combined with --thread.")
+			return
+		}
+		if (variantsFlag > 1 || threadFlag || perPlatform) && streamFlag {
+			fmt.Println("⚠️  --stream is not supported with --variants, --thread or --per-platform; the posts will be shown once generated.")
+			streamFlag = false
+		}
+
+		var fakeResponses []string
+		if fakeFlag != "" {
+			data, err := os.ReadFile(fakeFlag)
+			if err == nil {
+				err = json.Unmarshal(data, &fakeResponses)
+			}
+			if err != nil {
+				fmt.Println("❌ Failed to read --fake-responses, a JSON list of model outputs:", err)
+				return
+			}
+		}
 
+		// --provider (hidden, for testing) replaces the configured chain for this run
+		if providerFlag != "" && providerFlag != cfg.Provider {
+			cfg.Provider, cfg.APIKey, cfg.Fallbacks = providerFlag, "", nil
+		}
+		chain := cfg.ProviderChain()
 		fmt.Printf("📦 Using AI Provider: %s\n", cfg.Provider)
+		if len(chain) > 1 {
+			var fallbacks []string
+			for _, entry := range chain[1:] {
+				fallbacks = append(fallbacks, entry.Provider)
+			}
+			fmt.Printf("🛟 Fallbacks: %s\n", strings.Join(fallbacks, " → "))
+		}
+		if modelFlag != "" {
+			fmt.Printf("🧠 Model: %s\n", modelFlag)
+		}
 		fmt.Printf("📰 Target Platforms: %v\n\n", targetPlatforms)
 
 		// --- 4️⃣ Fetch commits from Git ---
@@ -89,45 +182,297 @@ Examples:
 		}
 
 		// --- 6️⃣ Generate posts via AI provider ---
-		provider, err := ai.NewProvider(cfg.Provider, cfg.APIKey)
+		var entries []ai.ChainEntry
+		for i, entry := range chain {
+			opts := providerOptions(entry.ProviderConfig)
+			// --model names a model of the primary provider; the temperature applies to all
+			if modelFlag != "" && i == 0 {
+				opts.Model = modelFlag
+			}
+			if cmd.Flags().Changed("temperature") {
+				opts.Temperature = &tempFlag
+			}
+			if fixturesFlag != "" {
+				opts.HTTPClient = ai.NewReplayClient(fixturesFlag, recordFlag)
+			}
+			opts.PluginPath = cfg.Plugins[entry.Provider]
+			opts.FakeResponses = fakeResponses
+
+			p, err := ai.NewProvider(entry.Provider, opts)
+			if err != nil {
+				fmt.Printf("❌ Error creating AI provider %s: %v\n", entry.Provider, err)
+				return
+			}
+			entries = append(entries, ai.ChainEntry{Name: entry.Provider, Provider: p})
+		}
+
+		templates, err := loadTemplates()
 		if err != nil {
-			fmt.Println("❌ Error creating AI provider:", err)
+			fmt.Println("❌ Failed to load prompt templates:", err)
 			return
 		}
+		for _, path := range templates.Overrides {
+			fmt.Printf("🧩 Using custom template %s\n", path)
+		}
 
-		posts, err := provider.GeneratePosts(commits, targetPlatforms, projectContext)
-		if err != nil {
-			fmt.Println("❌ Failed to generate posts:", err)
+		var voice *ai.Voice
+		voiceName := cfg.Voice
+		if voiceFlag != "" {
+			voiceName = voiceFlag
+		}
+		if voiceName != "" {
+			profile, ok := cfg.Voices[voiceName]
+			if !ok {
+				fmt.Printf("❌ Unknown voice profile %q. See `commitfeed voice list`.\n", voiceName)
+				return
+			}
+			voice = voiceOptions(voiceName, profile)
+			fmt.Printf("🎙️  Voice: %s\n", voiceName)
+		}
+
+		// --lang applies to every platform, replacing the configured per-platform languages
+		languages := cfg.Languages
+		if len(langFlag) > 0 {
+			languages = make(map[string][]string, len(targetPlatforms))
+			for _, p := range targetPlatforms {
+				languages[p] = langFlag
+			}
+		}
+
+		req := ai.Request{
+			Commits:         commits,
+			Platforms:       targetPlatforms,
+			ProjectContext:  projectContext,
+			Variants:        variantsFlag,
+			Thread:          threadFlag,
+			ShortenAttempts: shortenFlag,
+			Templates:       templates,
+			Voice:           voice,
+			Languages:       languages,
+		}
+		// --shorten-attempts 0 means truncate right away, which Request spells as a negative value
+		if shortenFlag <= 0 {
+			req.ShortenAttempts = -1
+		}
+
+		targets := req.Targets()
+		var labels []string
+		for _, t := range targets {
+			if t.Lang != "" {
+				labels = append(labels, t.Label())
+			}
+		}
+		if len(labels) > 0 {
+			fmt.Printf("🌐 Languages: %s\n", strings.Join(labels, ", "))
+		}
+
+		if showPrompt {
+			plan, err := ai.PlanPrompt(entries[0].Provider, req)
+			if err != nil {
+				fmt.Println("❌ Failed to build the prompt:", err)
+				return
+			}
+			fmt.Printf("📝 Prompt:\n%s\n", plan.Prompt)
+			model := plan.Model
+			if model == "" {
+				model = cfg.Provider
+			}
+			fmt.Printf("🔢 Estimated tokens: %d (budget for %s: %d)\n", plan.Tokens, model, plan.Budget)
+			if plan.Chunks > 0 {
+				fmt.Printf("✂️  Over budget: the commits would first be summarized in %d chunks, and the posts generated from the merged summaries.\n", plan.Chunks)
+			}
 			return
 		}
 
+		printer := newStreamPrinter()
+		provider := ai.NewChainProvider(entries...)
+		provider.OnFallback = func(failed string, err error, next string) {
+			printer.restart()
+			fmt.Printf("⚠️  %s failed (%v) — falling back to %s...\n", failed, err, next)
+		}
+		// When every provider is unavailable, posts are still written offline from the commits,
+		// except when replaying fixtures, where a missing one has to be reported
+		if fixturesFlag == "" && !slices.ContainsFunc(entries, func(e ai.ChainEntry) bool { return e.Name == ai.TemplateProviderName }) {
+			provider.LastResort = &ai.ChainEntry{Name: ai.TemplateProviderName, Provider: ai.NewTemplateProvider()}
+		}
+
+		// Ctrl-C cancels in-flight requests instead of killing the process mid-write
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		if timeoutFlag > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeoutFlag)
+			defer cancel()
+		}
+
+		// Count the tokens of every call in this run, and report them once it is over
+		meter := &ai.UsageMeter{}
+		ctx = ai.WithUsageMeter(ctx, meter)
+		defer func() { reportUsage(cfg, meter.Usage()) }()
+
+		// Reasoning is removed from the models' answers; --debug shows it once the run is over
+		if debugFlag {
+			reasoning := &ai.ReasoningLog{}
+			ctx = ai.WithReasoningLog(ctx, reasoning)
+			defer func() { printReasoning(reasoning.Traces()) }()
+		}
+
+		// Reuse an earlier generation for exactly the same input unless told otherwise
+		cacheKey, keyErr := generationCacheKey(entries[0], req, perPlatform)
+		useCache := !noCacheFlag && keyErr == nil
+
+		var posts *ai.GeneratedPosts
+		if useCache && !refreshFlag {
+			var hit ai.GeneratedPosts
+			if ok, _ := cache.Get(cacheKey, &hit); ok {
+				posts = &hit
+				fmt.Println("⚡ Using cached posts from an earlier run (use --refresh to regenerate).")
+			}
+		}
+
+		if posts == nil {
+			if !checkBudget(cfg, nil) {
+				return
+			}
+			switch {
+			case perPlatform:
+				posts, err = ai.GeneratePerPlatform(ctx, provider, req, concurrency)
+			case streamFlag:
+				posts, err = provider.StreamPosts(ctx, req, printer.onDelta)
+				printer.finish()
+			default:
+				posts, err = provider.GeneratePosts(ctx, req)
+			}
+
+			// Keep the platforms that succeeded, and only show and post those
+			var failed *ai.PlatformError
+			if errors.As(err, &failed) && posts != nil {
+				for _, p := range failed.Platforms {
+					fmt.Printf("❌ %s %s failed: %v\n", platformIcon(p), ai.PlatformLabel(p), failed.Errors[p])
+					if hint := errorHint(failed.Errors[p]); hint != "" {
+						fmt.Println("💡", hint)
+					}
+				}
+				req.Platforms = slices.DeleteFunc(slices.Clone(req.Platforms), func(p string) bool { return slices.Contains(failed.Platforms, p) })
+				targets = req.Targets()
+				err = nil
+			}
+			if errors.Is(err, context.Canceled) {
+				fmt.Println("\n🛑 Cancelled — no posts were generated.")
+				stop()
+				// os.Exit skips the deferred report, but the tokens spent before the cancel still count
+				reportUsage(cfg, meter.Usage())
+				os.Exit(130)
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				fmt.Printf("⏱️  Timed out after %s waiting for %s. Try again or raise --timeout.\n", timeoutFlag, cfg.Provider)
+				return
+			}
+			if err != nil {
+				fmt.Println("❌ Failed to generate posts:", err)
+				if hint := errorHint(err); hint != "" {
+					fmt.Println("💡", hint)
+				}
+				var invalid *ai.InvalidOutputError
+				if errors.As(err, &invalid) && invalid.Output != "" {
+					fmt.Printf("📄 Last model output:\n%s\n", invalid.Output)
+				}
+				return
+			}
+
+			// Offline posts stand in for the configured provider's; the next run should try it again
+			var offline []string
+			fromPrimary := true
+			for _, t := range targets {
+				provider := posts.PostProvider(t.Key())
+				if provider != entries[0].Name {
+					fromPrimary = false
+				}
+				if provider == ai.TemplateProviderName && entries[0].Name != ai.TemplateProviderName {
+					offline = append(offline, t.Label())
+				}
+			}
+			if len(offline) == len(targets) {
+				fmt.Println("📝 Every provider failed, so these posts were written offline from the commits.")
+			} else if len(offline) > 0 {
+				fmt.Printf("📝 Every provider failed for %s, so those posts were written offline from the commits.\n", strings.Join(offline, ", "))
+			}
+			// The cache is keyed on the primary provider, so a fallback's posts must not answer for it
+			if useCache && failed == nil && fromPrimary {
+				if err := cache.Put(cacheKey, posts); err != nil {
+					fmt.Printf("⚠️  Could not cache generated posts: %v\n", err)
+				}
+			}
+		}
+
+		if variantsFlag > 1 {
+			if err := pickVariants(posts, targets); err != nil {
+				fmt.Println("❌", err)
+				return
+			}
+		}
+
 		// --- 7️⃣ Output results ---
-		fmt.Println("✅ Generated Posts:")
-		for _, p := range targetPlatforms {
-			switch p {
-			case "linkedin":
-				fmt.Printf("🔗 LinkedIn:\n%s\n\n", posts.LinkedIn)
-			case "twitter":
-				fmt.Printf("🐦 Twitter:\n%s\n\n", posts.Twitter)
+		// Streamed posts are already on screen unless the final result differs (e.g. after a repair)
+		if !streamFlag || !printer.matches(posts, targets) {
+			switch {
+			case streamFlag && len(printer.streamed) > 0:
+				fmt.Println("🔁 The streamed output was repaired or shortened. Final posts:")
+			case fallbackNote(posts, targets, cfg.Provider) != "":
+				fmt.Printf("✅ Generated Posts (%s):\n", fallbackNote(posts, targets, cfg.Provider))
 			default:
-				fmt.Printf("📢 %s:\n%s\n\n", p, posts.LinkedIn)
+				fmt.Println("✅ Generated Posts:")
+			}
+			for _, t := range targets {
+				printPost(t, posts.Posts[t.Key()])
+			}
+		} else {
+			var lengths []string
+			for _, t := range targets {
+				lengths = append(lengths, fmt.Sprintf("%s %s", t.Label(), lengthLabel(t.Platform, posts.Get(t.Key()))))
+			}
+			fmt.Printf("📏 %s\n", strings.Join(lengths, " · "))
+			if note := fallbackNote(posts, targets, cfg.Provider); note != "" {
+				fmt.Printf("ℹ️  Posts generated %s.\n\n", note)
 			}
 		}
 
+		// Flag claims the commits do not back up; posting them needs --force
+		var unverified string // why posting needs --force, if it does
+		// Verifying calls the model again, so it counts against the budget like generating
+		if verifyFlag && !checkBudget(cfg, meter.Usage()) {
+			unverified = "the monthly budget is used up, so the posts were not verified"
+			fmt.Println()
+		} else if verifyFlag {
+			fmt.Println("🔎 Checking the posts against the commits...")
+			claims, err := ai.VerifyPosts(ctx, provider, req, posts)
+			switch {
+			case err != nil:
+				unverified = "the posts could not be verified against the commits"
+				fmt.Println("⚠️  Could not verify the posts:", err)
+			case len(claims) == 0:
+				fmt.Println("✅ Every claim is supported by the commits.")
+			default:
+				unverified = "the posts make claims the commits do not support"
+				for _, t := range targets {
+					if len(claims[t.Key()]) > 0 {
+						printClaims(t, claims[t.Key()])
+					}
+				}
+			}
+			fmt.Println()
+		}
+
 		// --- 8️⃣ Handle posting ---
-		if postFlag {
+		if postFlag && unverified != "" && !forceFlag {
+			fmt.Printf("🛑 Not posting: %s. Regenerate them, or use --force to post anyway.\n", unverified)
+		} else if postFlag {
 			fmt.Println("🚀 Posting to selected platforms...")
 
-			// This is where the posting logic will be added
-			for _, p := range targetPlatforms {
-				switch p {
-				case "linkedin":
-					fmt.Println("🔗 Posted to LinkedIn successfully (placeholder).")
-				case "twitter":
-					fmt.Println("🐦 Posted to Twitter successfully (placeholder).")
-				default:
-					fmt.Printf("📢 Skipped unknown platform: %s\n", p)
-				}
+			// This is where the posting logic will be added. Each language
+			// version is published as a post of its own.
+			for _, t := range targets {
+				publishPost(t, posts.Posts[t.Key()])
 			}
 		} else {
 			fmt.Println("💡 Preview only (not posted). Use --post to share automatically.")
@@ -135,10 +480,314 @@ Examples:
 	},
 }
 
+// generationCacheKey identifies a generation by everything that affects its output
+func generationCacheKey(primary ai.ChainEntry, req ai.Request, perPlatform bool) (string, error) {
+	// A single post is keyed as before variants existed, keeping older entries valid
+	variants := 0
+	if req.Variants > 1 {
+		variants = req.Variants
+	}
+	// The default number of attempts is keyed as before the flag existed
+	shorten := req.ShortenAttempts
+	if shorten == ai.DefaultShortenAttempts {
+		shorten = 0
+	}
+	var languages []string
+	for _, t := range req.Targets() {
+		if t.Lang != "" {
+			languages = append(languages, t.Key())
+		}
+	}
+	return cache.Key(struct {
+		PromptVersion  int          `json:"prompt_version"`
+		Provider       string       `json:"provider"`
+		Model          string       `json:"model"`
+		Platforms      []string     `json:"platforms"`
+		ProjectContext string       `json:"project_context"`
+		Commits        []git.Commit `json:"commits"`
+		Variants       int          `json:"variants,omitempty"`
+		Thread         bool         `json:"thread,omitempty"`
+		Shorten        int          `json:"shorten_attempts,omitempty"`
+		Templates      string       `json:"templates,omitempty"`
+		Voice          *ai.Voice    `json:"voice,omitempty"`
+		Languages      []string     `json:"languages,omitempty"`
+		PerPlatform    bool         `json:"per_platform,omitempty"`
+	}{
+		PromptVersion:  ai.PromptVersion,
+		Provider:       primary.Name,
+		Model:          ai.ModelName(primary.Provider),
+		Platforms:      req.Platforms,
+		ProjectContext: req.ProjectContext,
+		Commits:        req.Commits,
+		Variants:       variants,
+		Thread:         req.Thread,
+		Shorten:        shorten,
+		Templates:      req.Templates.Fingerprint(),
+		Voice:          req.Voice,
+		Languages:      languages,
+		PerPlatform:    perPlatform,
+	})
+}
+
+// streamPrinter renders streamed post text under each post's header
+type streamPrinter struct {
+	// mu guards the printer, which fallbacks may restart from several
+	// goroutines at once when platforms are generated separately
+	mu       sync.Mutex
+	current  string
+	streamed map[string]*strings.Builder
+}
+
+func newStreamPrinter() *streamPrinter {
+	return &streamPrinter{streamed: make(map[string]*strings.Builder)}
+}
+
+func (s *streamPrinter) onDelta(key, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key != s.current {
+		if s.current == "" {
+			fmt.Println("✅ Generated Posts:")
+		} else {
+			fmt.Print("\n\n")
+		}
+		t := ai.ParseTarget(key)
+		fmt.Printf("%s %s:\n", platformIcon(t.Platform), t.Label())
+		s.current = key
+	}
+	if s.streamed[key] == nil {
+		s.streamed[key] = &strings.Builder{}
+	}
+	s.streamed[key].WriteString(text)
+	fmt.Print(text)
+}
+
+// finish ends the output of the current stream
+func (s *streamPrinter) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.end()
+}
+
+// end ends the output of the current stream; s.mu must be held
+func (s *streamPrinter) end() {
+	if s.current != "" {
+		fmt.Print("\n\n")
+	}
+	s.current = ""
+}
+
+// restart discards what was streamed so far, e.g. before a fallback provider starts over
+func (s *streamPrinter) restart() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.end()
+	s.streamed = make(map[string]*strings.Builder)
+}
+
+// matches reports whether the text streamed to the terminal is the final text of every post
+func (s *streamPrinter) matches(posts *ai.GeneratedPosts, targets []ai.Target) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range targets {
+		sb := s.streamed[t.Key()]
+		if sb == nil || strings.TrimSpace(sb.String()) != posts.Get(t.Key()) {
+			return false
+		}
+	}
+	return true
+}
+
+// fallbackNote says which posts were written by a provider other than primary,
+// e.g. "via fallback provider ollama" or "by fallback providers: LinkedIn via
+// ollama", or returns "" when primary wrote them all
+func fallbackNote(posts *ai.GeneratedPosts, targets []ai.Target, primary string) string {
+	var others []string
+	providers := make(map[string]bool)
+	for _, t := range targets {
+		p := posts.PostProvider(t.Key())
+		providers[p] = true
+		if p != primary {
+			others = append(others, fmt.Sprintf("%s via %s", t.Label(), p))
+		}
+	}
+	switch {
+	case len(others) == 0:
+		return ""
+	case len(others) == len(targets) && len(providers) == 1:
+		return "via fallback provider " + posts.PostProvider(targets[0].Key())
+	default:
+		return "by fallback providers: " + strings.Join(others, ", ")
+	}
+}
+
+// providerOptions converts a provider's config entry into AI provider options
+func providerOptions(pc config.ProviderConfig) ai.Options {
+	return ai.Options{
+		APIKey:         pc.APIKey,
+		BaseURL:        pc.BaseURL,
+		Model:          pc.Model,
+		Headers:        pc.Headers,
+		AuthScheme:     pc.AuthScheme,
+		ResponseFormat: pc.ResponseFormat,
+		Temperature:    pc.Temperature,
+		TopP:           pc.TopP,
+		MaxTokens:      pc.MaxTokens,
+	}
+}
+
+// errorHint suggests what the user can do about a provider error
+func errorHint(err error) string {
+	var apiErr *ai.APIError
+	if !errors.As(err, &apiErr) {
+		return ""
+	}
+	provider := apiErr.Provider
+	switch apiErr.Kind {
+	case ai.ErrAuth:
+		return fmt.Sprintf("Check the API key for %s in ~/.commit-feed/config.json or set COMMITFEED_API_KEY.", provider)
+	case ai.ErrRateLimit:
+		if apiErr.RetryAfter > 0 {
+			return fmt.Sprintf("%s is rate limiting requests. Try again in %s.", provider, apiErr.RetryAfter.Round(time.Second))
+		}
+		return fmt.Sprintf("%s is rate limiting requests. Wait a moment and try again.", provider)
+	case ai.ErrQuota:
+		return fmt.Sprintf("Your %s quota or credits are used up. Check your plan or switch provider with `commitfeed init`.", provider)
+	case ai.ErrContentFiltered:
+		return "The provider's safety filter blocked the output. Try a different model or a narrower --range."
+	case ai.ErrBadRequest:
+		return "The provider rejected the request. Check the configured model name and sampling settings."
+	case ai.ErrServer:
+		return fmt.Sprintf("%s is having problems right now. Try again later.", provider)
+	}
+	return ""
+}
+
+// printPost prints a post under its target's header, part by part for threads
+func printPost(t ai.Target, post ai.Post) {
+	if len(post.Parts) == 0 {
+		fmt.Printf("%s %s (%s):\n%s\n\n", platformIcon(t.Platform), t.Label(), lengthLabel(t.Platform, post.Text), post.Text)
+		return
+	}
+	fmt.Printf("%s %s thread (%d parts):\n", platformIcon(t.Platform), t.Label(), len(post.Parts))
+	for _, part := range post.Parts {
+		fmt.Printf("%s\n   📏 %s\n\n", part, lengthLabel(t.Platform, part))
+	}
+}
+
+// printReasoning shows the reasoning traces removed from the models' answers
+func printReasoning(traces []ai.Reasoning) {
+	if len(traces) == 0 {
+		fmt.Println("🧠 The models returned no reasoning.")
+		return
+	}
+	for i, r := range traces {
+		fmt.Printf("🧠 Reasoning %d/%d from %s (%s):\n%s\n\n", i+1, len(traces), r.Provider, r.Model, r.Text)
+	}
+}
+
+// printClaims lists the claims of a post that the commits do not support
+func printClaims(t ai.Target, claims []ai.UnsupportedClaim) {
+	fmt.Printf("🚩 %s %s — %d unsupported claim(s):\n", platformIcon(t.Platform), t.Label(), len(claims))
+	for _, c := range claims {
+		if c.Reason == "" {
+			fmt.Printf("   • %q\n", c.Claim)
+			continue
+		}
+		fmt.Printf("   • %q — %s\n", c.Claim, c.Reason)
+	}
+}
+
+// publishPost publishes a post, or its parts as a thread, on a platform CommitFeed knows
+func publishPost(t ai.Target, post ai.Post) {
+	if !ai.KnownPlatform(t.Platform) {
+		fmt.Printf("📢 Skipped unknown platform: %s\n", t.Key())
+		return
+	}
+	if len(post.Parts) > 1 {
+		publishThread(t, post.Parts)
+		return
+	}
+	// This is where the post will be published
+	fmt.Printf("%s Posted to %s successfully (placeholder).\n", platformIcon(t.Platform), t.Label())
+}
+
+// publishThread posts the parts of a thread in order, each as a reply to the one before it
+func publishThread(t ai.Target, parts []string) {
+	icon := platformIcon(t.Platform)
+	for i := range parts {
+		// This is where each part will be posted in reply to the previous one
+		if i == 0 {
+			fmt.Printf("%s Posted 1/%d to %s successfully (placeholder).\n", icon, len(parts), t.Label())
+			continue
+		}
+		fmt.Printf("%s Posted %d/%d to %s in reply to %d/%d (placeholder).\n", icon, i+1, len(parts), t.Label(), i, len(parts))
+	}
+}
+
+// lengthLabel shows a post's length the way its platform counts it, against the limit if there is one
+func lengthLabel(platform, text string) string {
+	n := ai.PostLength(platform, text)
+	limit := ai.CharLimit(platform)
+	switch {
+	case limit == 0:
+		return fmt.Sprintf("%d chars", n)
+	case n > limit:
+		return fmt.Sprintf("⚠️  %d/%d chars, over the limit", n, limit)
+	}
+	return fmt.Sprintf("%d/%d chars", n, limit)
+}
+
+// platformIcon returns the emoji shown next to a platform's post
+func platformIcon(platform string) string {
+	switch platform {
+	case "linkedin":
+		return "🔗"
+	case "twitter":
+		return "🐦"
+	case "mastodon":
+		return "🐘"
+	case "bluesky":
+		return "🦋"
+	case "reddit":
+		return "👽"
+	case "devto":
+		return "📝"
+	default:
+		return "📢"
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(generateCmd)
 
 	generateCmd.Flags().StringVarP(&rangeFlag, "range", "r", "HEAD", "Git commit range to summarize (e.g. HEAD~5..HEAD)")
-	generateCmd.Flags().StringSliceVarP(&platformsFlag, "platforms", "t", nil, "Comma-separated list of platforms (e.g. linkedin,twitter,reddit)")
+	generateCmd.Flags().StringSliceVarP(&platformsFlag, "platforms", "t", nil, "Comma-separated list of platforms (e.g. linkedin,twitter,mastodon,bluesky,reddit,devto)")
 	generateCmd.Flags().BoolVarP(&postFlag, "post", "p", false, "Post generated content to selected platforms")
+	generateCmd.Flags().BoolVar(&verifyFlag, "verify", false, "Check the posts against the commits and flag claims they do not support")
+	generateCmd.Flags().BoolVar(&forceFlag, "force", false, "Post even if --verify flagged unsupported claims or could not run")
+	generateCmd.Flags().StringVarP(&modelFlag, "model", "m", "", "Model to use, overriding the configured one (e.g. gpt-4o)")
+	generateCmd.Flags().BoolVar(&noCacheFlag, "no-cache", false, "Neither read nor write the response cache")
+	generateCmd.Flags().BoolVar(&refreshFlag, "refresh", false, "Ignore cached posts and regenerate (the new result is cached)")
+	generateCmd.Flags().BoolVar(&showPrompt, "show-prompt", false, "Print the prompt and its token estimate without calling the model")
+	generateCmd.Flags().BoolVar(&streamFlag, "stream", false, "Show posts as they are generated")
+	generateCmd.Flags().IntVar(&shortenFlag, "shorten-attempts", ai.DefaultShortenAttempts, "How often to ask the model to shorten a post over its platform's limit before truncating it (0 truncates right away)")
+	generateCmd.Flags().StringSliceVar(&langFlag, "lang", nil, "Comma-separated languages to write every post in, one post per language (e.g. fr,en), overriding the configured ones")
+	generateCmd.Flags().StringVar(&voiceFlag, "voice", "", "Voice profile to write in, overriding the configured default (see `commitfeed voice list`)")
+	generateCmd.Flags().BoolVar(&threadFlag, "thread", false, "Generate a numbered thread instead of a single post on Twitter/X, Bluesky and Mastodon")
+	generateCmd.Flags().IntVar(&variantsFlag, "variants", 1, "Number of alternative posts to generate per platform, to pick from interactively")
+	generateCmd.Flags().DurationVar(&timeoutFlag, "timeout", 2*time.Minute, "Maximum time to wait for the AI provider (0 disables the limit)")
+	generateCmd.Flags().Float32Var(&tempFlag, "temperature", 0, "Sampling temperature, overriding the configured one (e.g. 0.7)")
+	generateCmd.Flags().BoolVar(&perPlatform, "per-platform", false, "Generate each platform's posts with a request of its own, in parallel")
+	generateCmd.Flags().IntVar(&concurrency, "concurrency", ai.DefaultConcurrency, "Number of platforms to generate at once with --per-platform")
+	generateCmd.Flags().BoolVar(&debugFlag, "debug", false, "Show the reasoning that reasoning models write before their answers, which is otherwise removed")
+
+	// Testing aids: run without a network against the fake provider or recorded responses
+	generateCmd.Flags().StringVar(&providerFlag, "provider", "", "Provider to use instead of the configured ones (e.g. fake)")
+	generateCmd.Flags().StringVar(&fixturesFlag, "fixtures", "", "Directory of recorded provider responses to replay instead of calling the API")
+	generateCmd.Flags().BoolVar(&recordFlag, "record", false, "Call the API and record its responses into --fixtures")
+	generateCmd.Flags().StringVar(&fakeFlag, "fake-responses", "", "JSON file listing the raw model outputs --provider fake returns, in order")
+	for _, name := range []string{"provider", "fixtures", "record", "fake-responses"} {
+		generateCmd.Flags().MarkHidden(name)
+	}
 }
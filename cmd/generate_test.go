@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kurtiz/commit-feed/internals/ai"
+	"github.com/kurtiz/commit-feed/internals/git"
+)
+
+func TestGenerationCacheKey(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "prompt.tmpl"), []byte(`{{define "prompt"}}Custom{{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	templates, err := ai.LoadTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	primary := ai.ChainEntry{Name: "openai", Provider: ai.NewOpenAIProvider(ai.Options{APIKey: "k", Model: "gpt-4o-mini"})}
+	base := func() ai.Request {
+		return ai.Request{
+			Commits:         []git.Commit{{Hash: "a1b2c3d", Author: "Ada", Date: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC), Message: "feat: export widgets to CSV"}},
+			Platforms:       []string{"linkedin", "twitter"},
+			ProjectContext:  "Widgetly manages widgets.",
+			ShortenAttempts: ai.DefaultShortenAttempts,
+		}
+	}
+	key := func(primary ai.ChainEntry, req ai.Request, perPlatform bool) string {
+		t.Helper()
+		k, err := generationCacheKey(primary, req, perPlatform)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	original := key(primary, base(), false)
+	if again := key(primary, base(), false); again != original {
+		t.Fatal("the same generation has two keys")
+	}
+
+	changes := map[string]func(*ai.ChainEntry, *ai.Request, *bool){
+		"provider": func(p *ai.ChainEntry, _ *ai.Request, _ *bool) { p.Name = "openai-compatible" },
+		"model": func(p *ai.ChainEntry, _ *ai.Request, _ *bool) {
+			p.Provider = ai.NewOpenAIProvider(ai.Options{APIKey: "k", Model: "gpt-4o"})
+		},
+		"platforms":       func(_ *ai.ChainEntry, r *ai.Request, _ *bool) { r.Platforms = []string{"linkedin"} },
+		"project context": func(_ *ai.ChainEntry, r *ai.Request, _ *bool) { r.ProjectContext = "Widgetly exports widgets." },
+		"commits": func(_ *ai.ChainEntry, r *ai.Request, _ *bool) {
+			r.Commits = append(r.Commits, git.Commit{Hash: "e4f5a6b", Message: "fix: keep widget order"})
+		},
+		"commit message":   func(_ *ai.ChainEntry, r *ai.Request, _ *bool) { r.Commits[0].Message = "feat: export widgets to JSON" },
+		"variants":         func(_ *ai.ChainEntry, r *ai.Request, _ *bool) { r.Variants = 3 },
+		"thread":           func(_ *ai.ChainEntry, r *ai.Request, _ *bool) { r.Thread = true },
+		"shorten attempts": func(_ *ai.ChainEntry, r *ai.Request, _ *bool) { r.ShortenAttempts = -1 },
+		"templates":        func(_ *ai.ChainEntry, r *ai.Request, _ *bool) { r.Templates = templates },
+		"voice":            func(_ *ai.ChainEntry, r *ai.Request, _ *bool) { r.Voice = &ai.Voice{Name: "casual", Tone: "Relaxed"} },
+		"voice tone": func(_ *ai.ChainEntry, r *ai.Request, _ *bool) {
+			r.Voice = &ai.Voice{Name: "casual", Tone: "Relaxed and short"}
+		},
+		"languages": func(_ *ai.ChainEntry, r *ai.Request, _ *bool) {
+			r.Languages = map[string][]string{"linkedin": {"en", "fr"}}
+		},
+		"per platform": func(_ *ai.ChainEntry, _ *ai.Request, perPlatform *bool) { *perPlatform = true },
+	}
+	seen := map[string]string{original: "the original"}
+	for name, change := range changes {
+		p, req, perPlatform := primary, base(), false
+		change(&p, &req, &perPlatform)
+		k := key(p, req, perPlatform)
+		if other, ok := seen[k]; ok {
+			t.Errorf("changing the %s gives the key of %s", name, other)
+		}
+		seen[k] = name
+	}
+
+	// Settings at their defaults keep the keys of entries cached before they existed
+	unchanged := base()
+	unchanged.Variants = 1
+	unchanged.Templates = &ai.Templates{}
+	if key(primary, unchanged, false) != original {
+		t.Error("default settings change the key")
+	}
+}
@@ -14,17 +14,16 @@ import (
 // initCmd represents the init command
 var initCmd = &cobra.Command{
 	Use:   "init",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Short: "Run the setup wizard to create or update your config file.",
+	Long: `Run the interactive setup wizard and save the result to ~/.commit-feed/config.json.
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+The wizard lets you pick an AI provider and enter its API key. When you choose
+Ollama, it also asks for the daemon host and lists the locally installed models.
+Everything else in an existing config, such as fallbacks, voices, languages and
+prices, is kept. Canceling the wizard leaves the config as it was.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		_, err := config.EnsureExists()
-		if err != nil {
-			fmt.Println("❌ Failed to load config:", err)
+		if _, err := config.Setup(); err != nil {
+			fmt.Println("❌ Setup failed:", err)
 			os.Exit(1)
 		}
 	},
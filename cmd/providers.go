@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kurtiz/commit-feed/internals/ai"
+	"github.com/kurtiz/commit-feed/internals/config"
+)
+
+// describeTimeout bounds how long a plugin may take to describe itself
+const describeTimeout = 5 * time.Second
+
+// providersCmd groups the provider subcommands
+var providersCmd = &cobra.Command{
+	Use:   "providers",
+	Short: "Show the AI providers CommitFeed can use.",
+	Long: `CommitFeed has built-in providers, and runs any executable named
+commitfeed-provider-<name> on your PATH as the provider <name>. Plugins elsewhere
+can be listed in ~/.commit-feed/config.json:
+
+  "plugins": {"mistral": "/opt/commitfeed/mistral-plugin"}
+
+Select a plugin like any provider, e.g. "provider": "mistral". See the README for
+the JSON protocol plugins speak on stdin and stdout.`,
+}
+
+// providersListCmd lists the built-in and plugin providers
+var providersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the built-in and plugin providers.",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Println("❌ Failed to load config:", err)
+			os.Exit(1)
+		}
+		var inUse []string
+		for _, entry := range cfg.ProviderChain() {
+			inUse = append(inUse, entry.Provider)
+		}
+		marker := func(name string) string {
+			switch i := slices.Index(inUse, name); {
+			case i == 0:
+				return " (in use)"
+			case i > 0:
+				return " (fallback)"
+			}
+			return ""
+		}
+
+		fmt.Println("🧩 Built-in providers:")
+		for _, p := range ai.BuiltinProviders {
+			fmt.Printf("   %-20s %s%s\n", p.Name, p.Description, marker(p.Name))
+		}
+
+		plugins := ai.FindPlugins(cfg.Plugins)
+		fmt.Println("\n🔌 Plugin providers:")
+		if len(plugins) == 0 {
+			fmt.Printf("   None found. Put an executable named %s<name> on your PATH, or list one under \"plugins\" in ~/.commit-feed/config.json.\n", ai.PluginPrefix)
+			return
+		}
+		for _, p := range plugins {
+			ctx, cancel := context.WithTimeout(context.Background(), describeTimeout)
+			desc, err := ai.NewPluginProvider(p.Name, p.Path, ai.Options{}).Describe(ctx)
+			cancel()
+			switch {
+			case err != nil:
+				desc = "⚠️  " + strings.SplitN(err.Error(), "\n", 2)[0]
+			case desc == "":
+				desc = "(no description)"
+			}
+
+			note := ""
+			builtin := slices.ContainsFunc(ai.BuiltinProviders, func(b ai.ProviderInfo) bool { return b.Name == p.Name })
+			if builtin && !p.Configured {
+				note = " (hidden by the built-in provider of the same name)"
+			}
+			fmt.Printf("   %-20s %s%s%s\n      %s\n", p.Name, desc, marker(p.Name), note, p.Path)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(providersCmd)
+	providersCmd.AddCommand(providersListCmd)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kurtiz/commit-feed/internals/ai"
+	"github.com/kurtiz/commit-feed/internals/config"
+	"github.com/kurtiz/commit-feed/internals/git"
+)
+
+var (
+	exportRepo  bool
+	exportForce bool
+)
+
+// templatesCmd groups the prompt template subcommands
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Manage the prompt templates used to generate posts.",
+	Long: `The prompt sent to the AI provider is rendered from Go text/template files.
+CommitFeed looks for each template first in the repository's .commitfeed/templates/,
+then in ~/.commit-feed/templates/, and falls back to the built-in defaults.
+
+Templates get the commits, commit stats, project context and platform metadata;
+see the comments at the top of each exported file for the available fields.`,
+}
+
+// templatesExportCmd writes the default templates out for editing
+var templatesExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the default prompt templates to a directory for editing.",
+	Long: `Write the built-in prompt templates to ~/.commit-feed/templates/, or with --repo to
+the current repository's .commitfeed/templates/, so they can be customized.
+Existing files are kept unless --force is given.
+
+Examples:
+  # Customize the prompts for all repositories
+  commitfeed templates export
+
+  # Customize the prompts for this repository only
+  commitfeed templates export --repo`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, err := config.TemplatesDir()
+		if exportRepo {
+			var root string
+			root, err = git.RootDir()
+			dir = filepath.Join(root, config.RepoTemplatesDir)
+		}
+		if err != nil {
+			fmt.Println("❌ Failed to find the template directory:", err)
+			os.Exit(1)
+		}
+
+		written, skipped, err := ai.ExportTemplates(dir, exportForce)
+		for _, path := range written {
+			fmt.Println("📝 Wrote", path)
+		}
+		for _, path := range skipped {
+			fmt.Printf("⏭️  Kept existing %s (use --force to overwrite)\n", path)
+		}
+		if err != nil {
+			fmt.Println("❌ Failed to export templates:", err)
+			os.Exit(1)
+		}
+		fmt.Println("💡 Edit the files to customize your prompts; delete them to go back to the defaults.")
+	},
+}
+
+// loadTemplates loads the prompt templates, with the repository's overriding the user's
+func loadTemplates() (*ai.Templates, error) {
+	var dirs []string
+	if root, err := git.RootDir(); err == nil {
+		dirs = append(dirs, filepath.Join(root, config.RepoTemplatesDir))
+	}
+	if dir, err := config.TemplatesDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	return ai.LoadTemplates(dirs...)
+}
+
+func init() {
+	rootCmd.AddCommand(templatesCmd)
+	templatesCmd.AddCommand(templatesExportCmd)
+
+	templatesExportCmd.Flags().BoolVar(&exportRepo, "repo", false, "Export to the current repository's .commitfeed/templates/ instead")
+	templatesExportCmd.Flags().BoolVar(&exportForce, "force", false, "Overwrite templates that already exist")
+}
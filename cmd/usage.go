@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/kurtiz/commit-feed/internals/ai"
+	"github.com/kurtiz/commit-feed/internals/config"
+	"github.com/kurtiz/commit-feed/internals/usage"
+)
+
+var usageMonths int
+
+// usageCmd shows token usage and cost per month from the usage ledger
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show token usage and cost per month.",
+	Long: `Show the tokens used and what they cost, per month, from the local usage ledger
+(~/.commit-feed/usage.jsonl) that every generation is recorded in.
+
+Costs are computed from built-in list prices, which the "prices" table in
+~/.commit-feed/config.json overrides (US dollars per million tokens):
+
+  "prices": {"gpt-4o-mini": {"input": 0.15, "output": 0.6}}
+
+Set "monthly_budget" (US dollars) to stop generating once a month's cost reaches it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Println("❌ Failed to load config:", err)
+			os.Exit(1)
+		}
+		entries, err := usage.Load()
+		if err != nil {
+			fmt.Println("❌", err)
+			return
+		}
+		if len(entries) == 0 {
+			fmt.Println("No usage recorded yet. It is recorded whenever `commitfeed generate` calls a provider.")
+			return
+		}
+
+		months := usage.Totals(entries, usage.ByMonth)
+		if usageMonths > 0 && len(months) > usageMonths {
+			months = months[len(months)-usageMonths:]
+		}
+		fmt.Println("📊 Usage per month:")
+		for _, t := range months {
+			printTotal(t)
+		}
+
+		current := time.Now().Local().Format("2006-01")
+		var thisMonth []usage.Entry
+		for _, e := range entries {
+			if usage.ByMonth(e) == current {
+				thisMonth = append(thisMonth, e)
+			}
+		}
+		if len(thisMonth) > 0 {
+			fmt.Printf("\n🧠 %s by model:\n", current)
+			for _, t := range usage.Totals(thisMonth, usage.ByModel) {
+				printTotal(t)
+			}
+		}
+
+		if cfg.MonthlyBudget > 0 {
+			spent, unpriced := budgetStatus(cfg, entries, nil, time.Now())
+			fmt.Printf("\n💰 Monthly budget: %s of %s used\n", dollars(spent), dollars(cfg.MonthlyBudget))
+			if len(unpriced) > 0 {
+				fmt.Printf("⚠️  Not counting the unpriced usage of %s.\n", strings.Join(unpriced, ", "))
+			}
+		}
+	},
+}
+
+// printTotal prints one row of usage totals
+func printTotal(t usage.Total) {
+	cost := dollars(t.Cost)
+	if t.Estimated {
+		cost = "~" + cost
+	}
+	if t.Unpriced {
+		cost += " (some models unpriced)"
+	}
+	fmt.Printf("   %-28s %6d requests  %12s tokens  %s\n", t.Key, t.Requests, humanize.Comma(int64(t.PromptTokens+t.CompletionTokens)), cost)
+}
+
+// reportUsage prints the token usage and cost of a run and records it in the usage ledger
+func reportUsage(cfg *config.Config, used []ai.Usage) {
+	if len(used) == 0 {
+		return
+	}
+
+	prices := configPrices(cfg)
+	now := time.Now()
+	entries := make([]usage.Entry, 0, len(used))
+	for _, u := range used {
+		e := usageEntry(prices, u, now)
+		cost := "cost unknown — add its price under \"prices\" in ~/.commit-feed/config.json"
+		if !e.Unpriced {
+			cost = dollars(e.Cost)
+		}
+		tokens := fmt.Sprintf("%s prompt + %s completion tokens", humanize.Comma(int64(u.PromptTokens)), humanize.Comma(int64(u.CompletionTokens)))
+		if u.Estimated {
+			tokens = "~" + tokens + " (estimated)"
+		}
+		fmt.Printf("🧾 %s/%s: %s in %d request(s), %s\n", u.Provider, u.Model, tokens, u.Requests, cost)
+		entries = append(entries, e)
+	}
+
+	if err := usage.Record(entries...); err != nil {
+		fmt.Printf("⚠️  Could not record usage: %v\n", err)
+		return
+	}
+	if cfg.MonthlyBudget > 0 {
+		if all, err := usage.Load(); err == nil {
+			fmt.Printf("💰 %s of the %s monthly budget used.\n", dollars(usage.MonthCost(all, now)), dollars(cfg.MonthlyBudget))
+		}
+	}
+}
+
+// usageEntry prices the usage of a run for the ledger
+func usageEntry(prices map[string]usage.Price, u ai.Usage, now time.Time) usage.Entry {
+	e := usage.Entry{
+		Time:             now,
+		Provider:         u.Provider,
+		Model:            u.Model,
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		Requests:         u.Requests,
+		Estimated:        u.Estimated,
+	}
+	if price, ok := usage.Lookup(prices, u.Provider, u.Model); ok {
+		e.Cost = price.Cost(u.PromptTokens, u.CompletionTokens)
+	} else {
+		e.Unpriced = true
+	}
+	return e
+}
+
+// configPrices returns the price overrides from the config
+func configPrices(cfg *config.Config) map[string]usage.Price {
+	prices := make(map[string]usage.Price, len(cfg.Prices))
+	for key, p := range cfg.Prices {
+		prices[key] = usage.Price{Input: p.Input, Output: p.Output}
+	}
+	return prices
+}
+
+// checkBudget reports whether the monthly budget, if any, still allows calling
+// a provider. pending is the usage of the current run, which is only recorded
+// in the ledger once the run is over.
+func checkBudget(cfg *config.Config, pending []ai.Usage) bool {
+	if cfg.MonthlyBudget <= 0 {
+		return true
+	}
+	entries, err := usage.Load()
+	if err != nil {
+		fmt.Printf("⚠️  Could not check the monthly budget: %v\n", err)
+		return true
+	}
+	spent, unpriced := budgetStatus(cfg, entries, pending, time.Now())
+	if len(unpriced) > 0 {
+		fmt.Printf("⚠️  No price is known for %s, so the monthly budget cannot count it. Add its price under \"prices\" in ~/.commit-feed/config.json.\n", strings.Join(unpriced, ", "))
+	}
+	if spent >= cfg.MonthlyBudget {
+		fmt.Printf("🛑 %s spent this month, which reaches the monthly budget of %s.\n", dollars(spent), dollars(cfg.MonthlyBudget))
+		fmt.Println("💡 Raise monthly_budget in ~/.commit-feed/config.json or wait until next month. Cached posts can still be shown.")
+		return false
+	}
+	return true
+}
+
+// budgetStatus returns what was spent in the month of now, from the ledger
+// entries and the pending usage of the current run, and the models used that
+// month whose cost is unknown
+func budgetStatus(cfg *config.Config, entries []usage.Entry, pending []ai.Usage, now time.Time) (float64, []string) {
+	prices := configPrices(cfg)
+	month := now.Local().Format("2006-01")
+	var thisMonth []usage.Entry
+	for _, e := range entries {
+		if usage.ByMonth(e) == month {
+			thisMonth = append(thisMonth, e)
+		}
+	}
+	for _, u := range pending {
+		thisMonth = append(thisMonth, usageEntry(prices, u, now))
+	}
+	return usage.MonthCost(thisMonth, now), usage.UnpricedModels(thisMonth)
+}
+
+// dollars formats an amount in US dollars, with more precision for small amounts
+func dollars(v float64) string {
+	if v < 1 {
+		return fmt.Sprintf("$%.4f", v)
+	}
+	return fmt.Sprintf("$%.2f", v)
+}
+
+func init() {
+	rootCmd.AddCommand(usageCmd)
+	usageCmd.Flags().IntVar(&usageMonths, "months", 12, "Number of recent months to show (0 shows all)")
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kurtiz/commit-feed/internals/ai"
+	"github.com/kurtiz/commit-feed/internals/config"
+	"github.com/kurtiz/commit-feed/internals/usage"
+)
+
+func TestBudgetStatus(t *testing.T) {
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.Local)
+	cfg := &config.Config{
+		MonthlyBudget: 5,
+		Prices:        map[string]config.Price{"my-model": {Input: 1000, Output: 0}},
+	}
+	ledger := []usage.Entry{
+		{Time: now.AddDate(0, -1, 0), Provider: "openai", Model: "gpt-4o", Cost: 100},
+		{Time: now.Add(-time.Hour), Provider: "openai", Model: "gpt-4o", Cost: 1.5},
+		{Time: now.Add(-time.Hour), Provider: "plugin", Model: "x", Unpriced: true},
+	}
+
+	tests := []struct {
+		name     string
+		ledger   []usage.Entry
+		pending  []ai.Usage
+		spent    float64
+		unpriced []string
+	}{
+		{"empty", nil, nil, 0, nil},
+		{"this month only", ledger, nil, 1.5, []string{"plugin/x"}},
+		{"pending priced", ledger, []ai.Usage{{Provider: "openai-compatible", Model: "my-model", PromptTokens: 3000}}, 4.5, []string{"plugin/x"}},
+		{"pending unpriced", nil, []ai.Usage{{Provider: "openai-compatible", Model: "mystery", PromptTokens: 3000}}, 0, []string{"openai-compatible/mystery"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spent, unpriced := budgetStatus(cfg, tt.ledger, tt.pending, now)
+			if math.Abs(spent-tt.spent) > 1e-9 {
+				t.Errorf("spent = %v, want %v", spent, tt.spent)
+			}
+			if !reflect.DeepEqual(unpriced, tt.unpriced) {
+				t.Errorf("unpriced = %q, want %q", unpriced, tt.unpriced)
+			}
+		})
+	}
+}
+
+func TestCheckBudget(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := usage.Record(usage.Entry{Time: time.Now(), Provider: "openai", Model: "gpt-4o", Cost: 4}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{MonthlyBudget: 5}
+
+	if !checkBudget(&config.Config{}, nil) {
+		t.Error("no budget blocked the run")
+	}
+	if !checkBudget(cfg, nil) {
+		t.Error("$4 of $5 blocked the run")
+	}
+	// 400k gpt-4o prompt tokens cost $1, reaching the budget
+	if checkBudget(cfg, []ai.Usage{{Provider: "openai", Model: "gpt-4o", PromptTokens: 400_000}}) {
+		t.Error("pending usage did not count against the budget")
+	}
+	if !checkBudget(cfg, []ai.Usage{{Provider: "plugin", Model: "x", PromptTokens: 400_000}}) {
+		t.Error("unpriced usage blocked the run")
+	}
+}
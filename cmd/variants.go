@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+
+	"github.com/kurtiz/commit-feed/internals/ai"
+)
+
+// mixVariants is the select value for combining variants in the editor
+const mixVariants = -1
+
+// pickVariants lets the user choose, mix or edit one of the generated variants
+// for each post. Without a terminal to ask in, the first variant is kept.
+func pickVariants(posts *ai.GeneratedPosts, targets []ai.Target) error {
+	if !isTerminal(os.Stdin) {
+		fmt.Println("ℹ️  Not running in a terminal — keeping the first variant of each post.")
+		return nil
+	}
+
+	for _, t := range targets {
+		post, ok := posts.Posts[t.Key()]
+		if !ok || len(post.Variants) < 2 {
+			continue
+		}
+
+		p := t.Platform
+		label := t.Label()
+		fmt.Printf("%s %s — %d variants:\n\n", platformIcon(p), label, len(post.Variants))
+		options := make([]huh.Option[int], 0, len(post.Variants)+1)
+		for i, v := range post.Variants {
+			fmt.Printf("[%d] (%s)\n%s\n\n", i+1, lengthLabel(p, v), v)
+			options = append(options, huh.NewOption(fmt.Sprintf("%d: %s", i+1, preview(v, 60)), i))
+		}
+		options = append(options, huh.NewOption("🧩 Mix — combine variants in the editor", mixVariants))
+
+		choice := 0
+		if err := huh.NewSelect[int]().
+			Title(fmt.Sprintf("Which %s post do you want to use?", label)).
+			Options(options...).
+			Value(&choice).
+			Run(); err != nil {
+			return fmt.Errorf("selection cancelled: %v", err)
+		}
+
+		var text string
+		edit := choice == mixVariants
+		if edit {
+			text = strings.Join(post.Variants, "\n\n")
+		} else {
+			text = post.Variants[choice]
+			if err := huh.NewConfirm().
+				Title("Edit it before continuing?").
+				Value(&edit).
+				Run(); err != nil {
+				return fmt.Errorf("selection cancelled: %v", err)
+			}
+		}
+
+		if edit {
+			if err := huh.NewText().
+				Title(fmt.Sprintf("Edit the %s post", label)).
+				Description("Ctrl+E opens your $EDITOR").
+				CharLimit(0).
+				Lines(10).
+				Value(&text).
+				Run(); err != nil {
+				return fmt.Errorf("editing cancelled: %v", err)
+			}
+		}
+
+		if text = strings.TrimSpace(text); text != "" {
+			post.Text = text
+		}
+		posts.Posts[t.Key()] = post
+	}
+	return nil
+}
+
+// preview returns the first line of text, shortened to at most n runes
+func preview(text string, n int) string {
+	line, _, _ := strings.Cut(text, "\n")
+	if r := []rune(line); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return line
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kurtiz/commit-feed/internals/ai"
+	"github.com/kurtiz/commit-feed/internals/config"
+)
+
+var (
+	importPlatform string
+	importLimit    int
+	importReplace  bool
+)
+
+// voiceCmd groups the voice profile subcommands
+var voiceCmd = &cobra.Command{
+	Use:   "voice",
+	Short: "Manage the voice profiles posts are written in.",
+	Long: `Voice profiles make generated posts sound like your team. Each profile in
+~/.commit-feed/config.json has a tone description, banned phrases, an emoji policy
+("none", "sparing" or "free") and example posts, which are shown to the model as
+demonstrations of the voice:
+
+  "voices": {
+    "team": {
+      "tone": "Plain-spoken and precise, with a dry sense of humour.",
+      "banned_phrases": ["game-changer", "excited to announce"],
+      "emoji": "sparing",
+      "examples": [{"platform": "linkedin", "text": "..."}]
+    }
+  },
+  "voice": "team"
+
+Select a profile with generate --voice <name>, or set "voice" to use one by default.`,
+}
+
+// voiceImportCmd adds example posts to a profile from an export of earlier posts
+var voiceImportCmd = &cobra.Command{
+	Use:   "import <profile> <file>",
+	Short: "Import example posts into a voice profile from a text or JSON export.",
+	Long: `Import example posts into a voice profile, creating the profile if needed.
+
+The file may be:
+  • a JSON list of posts, either strings or objects with "text", "full_text",
+    "content" or "commentary" (and optionally "platform")
+  • the tweets.js file of an X archive
+  • a text file with one post per block, separated by "---" lines or blank lines
+
+Examples:
+  # Import tweets from an X archive
+  commitfeed voice import team data/tweets.js
+
+  # Import LinkedIn posts saved to a text file, replacing earlier examples
+  commitfeed voice import team linkedin.txt --platform linkedin --replace`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, file := args[0], args[1]
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Println("❌ Failed to read export:", err)
+			os.Exit(1)
+		}
+
+		platform := ""
+		if importPlatform != "" {
+			platform = ai.NormalizePlatform(importPlatform)
+		}
+		examples, err := config.ParseVoiceExamples(data, platform)
+		if err != nil {
+			fmt.Println("❌ Failed to parse export:", err)
+			os.Exit(1)
+		}
+		if len(examples) == 0 {
+			fmt.Println("⚠️  No posts found in", file)
+			return
+		}
+		// Keep the first posts of the export, which are usually the most recent
+		if importLimit > 0 && len(examples) > importLimit {
+			examples = examples[:importLimit]
+		}
+
+		var total int
+		err = config.Update(func(cfg *config.Config) error {
+			if cfg.Voices == nil {
+				cfg.Voices = make(map[string]config.VoiceProfile)
+			}
+			profile := cfg.Voices[name]
+			if importReplace {
+				profile.Examples = nil
+			}
+			for _, ex := range examples {
+				if !slices.Contains(profile.Examples, ex) {
+					profile.Examples = append(profile.Examples, ex)
+				}
+			}
+			cfg.Voices[name] = profile
+			total = len(profile.Examples)
+			return nil
+		})
+		if err != nil {
+			fmt.Println("❌ Failed to save voice profile:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🎙️  Imported %d example posts into %q (%d in total).\n", len(examples), name, total)
+	},
+}
+
+// voiceListCmd shows the configured voice profiles
+var voiceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configured voice profiles.",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Println("❌ Failed to load config:", err)
+			os.Exit(1)
+		}
+		if len(cfg.Voices) == 0 {
+			fmt.Println("No voice profiles configured. Import one with `commitfeed voice import <profile> <file>`.")
+			return
+		}
+
+		names := make([]string, 0, len(cfg.Voices))
+		for name := range cfg.Voices {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			profile := cfg.Voices[name]
+			marker := "  "
+			if name == cfg.Voice {
+				marker = "⭐"
+			}
+			fmt.Printf("%s %s — %d examples", marker, name, len(profile.Examples))
+			if profile.Emoji != "" {
+				fmt.Printf(", emoji: %s", profile.Emoji)
+			}
+			fmt.Println()
+			if profile.Tone != "" {
+				fmt.Printf("   %s\n", profile.Tone)
+			}
+			if len(profile.BannedPhrases) > 0 {
+				fmt.Printf("   banned: %s\n", strings.Join(profile.BannedPhrases, ", "))
+			}
+		}
+	},
+}
+
+// voiceOptions converts a voice profile from the config into the AI voice
+func voiceOptions(name string, profile config.VoiceProfile) *ai.Voice {
+	voice := &ai.Voice{
+		Name:          name,
+		Tone:          profile.Tone,
+		BannedPhrases: profile.BannedPhrases,
+		Emoji:         strings.ToLower(profile.Emoji),
+	}
+	for _, ex := range profile.Examples {
+		voice.Examples = append(voice.Examples, ai.VoiceExample{Platform: ex.Platform, Text: ex.Text})
+	}
+	return voice
+}
+
+func init() {
+	rootCmd.AddCommand(voiceCmd)
+	voiceCmd.AddCommand(voiceImportCmd, voiceListCmd)
+
+	voiceImportCmd.Flags().StringVar(&importPlatform, "platform", "", "Platform the imported posts were written for (e.g. linkedin)")
+	voiceImportCmd.Flags().IntVar(&importLimit, "limit", 20, "Import at most this many posts, from the top of the file (0 for all)")
+	voiceImportCmd.Flags().BoolVar(&importReplace, "replace", false, "Replace the profile's existing examples instead of adding to them")
+}
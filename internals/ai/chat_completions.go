@@ -0,0 +1,305 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Auth schemes supported by chatEndpoint
+const (
+	AuthBearer = "bearer"  // Authorization: Bearer <key>
+	AuthAPIKey = "api-key" // api-key: <key>
+	AuthNone   = "none"    // no auth header
+)
+
+// chatEndpoint describes an OpenAI-compatible chat completions endpoint
+type chatEndpoint struct {
+	// name is used in error messages
+	name       string
+	url        string
+	apiKey     string
+	authScheme string
+	headers    map[string]string
+	// streamUsage asks for token usage at the end of a stream, which not
+	// every OpenAI-compatible server accepts
+	streamUsage bool
+	// client sends the requests; nil uses the shared client
+	client *http.Client
+}
+
+// chatUsage is the usage block of an OpenAI-style response
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// httpClient returns the client the endpoint sends requests with
+func (e chatEndpoint) httpClient() *http.Client {
+	if e.client != nil {
+		return e.client
+	}
+	return httpClient
+}
+
+// payloadModel returns the model a request payload names
+func payloadModel(payload map[string]interface{}) string {
+	model, _ := payload["model"].(string)
+	return model
+}
+
+// payloadPrompt returns the text of a request payload's messages
+func payloadPrompt(payload map[string]interface{}) string {
+	var sb strings.Builder
+	messages, _ := payload["messages"].([]map[string]string)
+	for _, m := range messages {
+		sb.WriteString(m["content"])
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// complete posts an OpenAI-style chat completion request and returns the
+// content of the first choice
+func (e chatEndpoint) complete(ctx context.Context, payload map[string]interface{}) (string, error) {
+	choices, err := e.completeChoices(ctx, payload)
+	if err != nil {
+		return "", err
+	}
+	return choices[0], nil
+}
+
+// completeChoices posts an OpenAI-style chat completion request and returns
+// the content of every choice, of which there are several when the payload
+// sets "n". Choices removed by the content filter are skipped.
+func (e chatEndpoint) completeChoices(ctx context.Context, payload map[string]interface{}) ([]string, error) {
+	req, err := e.newRequest(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", e.name, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %v", e.name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(e.name, resp.StatusCode, resp.Header, data)
+	}
+
+	var parsed struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+				// DeepSeek returns the reasoning of its reasoner models in
+				// reasoning_content, Groq and OpenRouter in reasoning
+				ReasoningContent string `json:"reasoning_content"`
+				Reasoning        string `json:"reasoning"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage chatUsage `json:"usage"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %v", e.name, err)
+	}
+
+	model := payloadModel(payload)
+	var raw, contents []string
+	filtered := false
+	for _, choice := range parsed.Choices {
+		switch {
+		case choice.FinishReason == "content_filter":
+			filtered = true
+		case choice.Message.Content != "":
+			raw = append(raw, choice.Message.Content)
+			reasoning := choice.Message.ReasoningContent + choice.Message.Reasoning
+			if answer := cleanOutput(ctx, e.name, model, choice.Message.Content, reasoning); answer != "" {
+				contents = append(contents, answer)
+			}
+		}
+	}
+	recordUsage(ctx, e.name, model, parsed.Usage.PromptTokens, parsed.Usage.CompletionTokens, payloadPrompt(payload), raw...)
+	if len(contents) == 0 && filtered {
+		return nil, &APIError{Provider: e.name, Kind: ErrContentFiltered, Message: "the response was blocked by the provider's content filter"}
+	}
+	if len(contents) == 0 && len(raw) > 0 {
+		return nil, fmt.Errorf("%s returned only reasoning and no answer; the model may have run out of output tokens", e.name)
+	}
+	if len(contents) == 0 {
+		return nil, fmt.Errorf("no response content returned from %s", e.name)
+	}
+
+	return contents, nil
+}
+
+// stream posts a streaming chat completion request, calling onChunk with each
+// piece of the answer as it arrives, and returns the full answer
+func (e chatEndpoint) stream(ctx context.Context, payload map[string]interface{}, onChunk func(string)) (string, error) {
+	payload["stream"] = true
+	if e.streamUsage {
+		payload["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+	req, err := e.newRequest(ctx, payload)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := e.httpClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%s request failed: %w", e.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return "", newAPIError(e.name, resp.StatusCode, resp.Header, data)
+	}
+
+	model := payloadModel(payload)
+	filter := newReasoningFilter(model, onChunk)
+	var content, reasoning strings.Builder
+	var usage chatUsage
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := sseData(scanner.Text())
+		if !ok || data == "" {
+			continue
+		}
+		if data == "[DONE]" {
+			break
+		}
+
+		var event struct {
+			Choices []struct {
+				Delta struct {
+					Content          string `json:"content"`
+					ReasoningContent string `json:"reasoning_content"`
+					Reasoning        string `json:"reasoning"`
+				} `json:"delta"`
+				FinishReason string `json:"finish_reason"`
+			} `json:"choices"`
+			Usage *chatUsage      `json:"usage"`
+			Error json.RawMessage `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return "", fmt.Errorf("failed to parse %s stream event: %v", e.name, err)
+		}
+		if len(event.Error) > 0 && string(event.Error) != "null" {
+			return "", &APIError{Provider: e.name, Message: errorMessage([]byte(data))}
+		}
+		if event.Usage != nil {
+			usage = *event.Usage
+		}
+		if len(event.Choices) == 0 {
+			continue
+		}
+		if event.Choices[0].FinishReason == "content_filter" {
+			return "", &APIError{Provider: e.name, Kind: ErrContentFiltered, Message: "the response was blocked by the provider's content filter"}
+		}
+		delta := event.Choices[0].Delta
+		reasoning.WriteString(delta.ReasoningContent + delta.Reasoning)
+		if delta.Content != "" {
+			content.WriteString(delta.Content)
+			filter.Write(delta.Content)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("failed to read %s stream: %w", e.name, err)
+	}
+	recordUsage(ctx, e.name, model, usage.PromptTokens, usage.CompletionTokens, payloadPrompt(payload), content.String())
+	if content.Len() == 0 {
+		return "", fmt.Errorf("no response content returned from %s", e.name)
+	}
+	answer := cleanOutput(ctx, e.name, model, content.String(), reasoning.String())
+	if answer == "" {
+		return "", fmt.Errorf("%s returned only reasoning and no answer; the model may have run out of output tokens", e.name)
+	}
+
+	return answer, nil
+}
+
+// newRequest builds an authenticated JSON POST request to the endpoint
+func (e chatEndpoint) newRequest(ctx context.Context, payload map[string]interface{}) (*http.Request, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s request: %v", e.name, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %v", e.name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	if e.apiKey != "" {
+		switch strings.ToLower(e.authScheme) {
+		case AuthNone:
+		case AuthAPIKey:
+			req.Header.Set("api-key", e.apiKey)
+		default:
+			req.Header.Set("Authorization", "Bearer "+e.apiKey)
+		}
+	}
+	return req, nil
+}
+
+// sseData returns the payload of a server-sent events "data:" line
+func sseData(line string) (string, bool) {
+	line = strings.TrimRight(line, "\r")
+	if !strings.HasPrefix(line, "data:") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(line, "data:")), true
+}
+
+// chatMessagesPayload converts chat messages to the OpenAI wire format
+func chatMessagesPayload(messages []chatMessage) []map[string]string {
+	out := make([]map[string]string, 0, len(messages))
+	for _, m := range messages {
+		out = append(out, map[string]string{"role": m.Role, "content": m.Content})
+	}
+	return out
+}
+
+// applyChatParams adds the configured sampling parameters to an OpenAI-style request body
+func applyChatParams(body map[string]interface{}, opts Options) {
+	if opts.Temperature != nil {
+		body["temperature"] = *opts.Temperature
+	}
+	if opts.TopP != nil {
+		body["top_p"] = *opts.TopP
+	}
+	if opts.MaxTokens > 0 {
+		body["max_tokens"] = opts.MaxTokens
+	}
+}
+
+// jsonSchemaResponseFormat builds an OpenAI-style structured output response_format
+func jsonSchemaResponseFormat(schema *jsonSchema) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "json_schema",
+		"json_schema": map[string]interface{}{
+			"name":   "commitfeed_posts",
+			"schema": schema,
+			"strict": true,
+		},
+	}
+}
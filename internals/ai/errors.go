@@ -0,0 +1,171 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrorKind classifies provider failures so callers can react to them
+type ErrorKind int
+
+const (
+	ErrUnknown ErrorKind = iota
+	ErrAuth
+	ErrRateLimit
+	ErrQuota
+	ErrContentFiltered
+	ErrBadRequest
+	ErrServer
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrAuth:
+		return "authentication failed"
+	case ErrRateLimit:
+		return "rate limited"
+	case ErrQuota:
+		return "quota exceeded"
+	case ErrContentFiltered:
+		return "content filtered"
+	case ErrBadRequest:
+		return "bad request"
+	case ErrServer:
+		return "server error"
+	default:
+		return "request failed"
+	}
+}
+
+// APIError is returned when a provider rejects a request
+type APIError struct {
+	Provider   string
+	Kind       ErrorKind
+	StatusCode int
+	Message    string
+	// RetryAfter is the wait the provider asked for, if any
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s: %s", e.Provider, e.Kind)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" (%d)", e.StatusCode)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Retryable reports whether the same request may succeed if tried again later
+func (e *APIError) Retryable() bool {
+	return e.Kind == ErrRateLimit || e.Kind == ErrServer
+}
+
+// newAPIError classifies a failed HTTP response from a provider
+func newAPIError(provider string, status int, header http.Header, body []byte) *APIError {
+	message := errorMessage(body)
+	lower := strings.ToLower(string(body))
+
+	kind := ErrUnknown
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		kind = ErrAuth
+	case status == http.StatusPaymentRequired || isQuotaError(status, lower):
+		kind = ErrQuota
+	case status == http.StatusTooManyRequests:
+		kind = ErrRateLimit
+	case isContentFilterError(lower):
+		kind = ErrContentFiltered
+	case status >= 500:
+		kind = ErrServer
+	case status >= 400:
+		kind = ErrBadRequest
+	}
+
+	return &APIError{
+		Provider:   provider,
+		Kind:       kind,
+		StatusCode: status,
+		Message:    message,
+		RetryAfter: parseRetryAfter(header.Get("Retry-After")),
+	}
+}
+
+// isQuotaError reports whether a 429 is about exhausted credits rather than a short-term limit.
+// A bare "quota" is not enough: Gemini reports its per-minute limits as exceeded quotas.
+func isQuotaError(status int, body string) bool {
+	if status != http.StatusTooManyRequests {
+		return false
+	}
+	for _, marker := range []string{"insufficient_quota", "billing", "credits", "exceeded your monthly"} {
+		if strings.Contains(body, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func isContentFilterError(body string) bool {
+	for _, marker := range []string{"content_filter", "content_policy", "content management policy", "safety system"} {
+		if strings.Contains(body, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// errorMessage pulls the human-readable message out of a provider error body
+func errorMessage(body []byte) string {
+	var parsed struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		var nested struct {
+			Message string `json:"message"`
+		}
+		var flat string
+		switch {
+		case json.Unmarshal(parsed.Error, &nested) == nil && nested.Message != "":
+			return nested.Message
+		case json.Unmarshal(parsed.Error, &flat) == nil && flat != "":
+			return flat
+		case parsed.Message != "":
+			return parsed.Message
+		}
+	}
+
+	msg := strings.TrimSpace(string(body))
+	if len(msg) > 300 {
+		cut := 300
+		for cut > 0 && !utf8.RuneStart(msg[cut]) {
+			cut--
+		}
+		msg = msg[:cut] + "…"
+	}
+	return msg
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	var seconds int
+	if _, err := fmt.Sscanf(value, "%d", &seconds); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
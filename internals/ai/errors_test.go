@@ -0,0 +1,108 @@
+package ai
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"0", 0},
+		{"7", 7 * time.Second},
+		{" 120 ", 2 * time.Minute},
+		{"-3", 0},
+		{"soon", 0},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	// An HTTP date has whole seconds, so the wait is a little under the offset
+	date := time.Now().Add(90 * time.Second).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(date); got <= 88*time.Second || got > 90*time.Second {
+		t.Errorf("parseRetryAfter(%q) = %v, want about 90s", date, got)
+	}
+}
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		status    int
+		body      string
+		kind      ErrorKind
+		retryable bool
+	}{
+		{http.StatusUnauthorized, `{"error": {"message": "Incorrect API key provided"}}`, ErrAuth, false},
+		{http.StatusForbidden, `{"error": "Forbidden"}`, ErrAuth, false},
+		{http.StatusPaymentRequired, `{"error": {"message": "Insufficient Balance"}}`, ErrQuota, false},
+		{http.StatusTooManyRequests, `{"error": {"message": "Rate limit reached for requests"}}`, ErrRateLimit, true},
+		{http.StatusTooManyRequests, `{"error": {"message": "You exceeded your current quota", "code": "insufficient_quota"}}`, ErrQuota, false},
+		{http.StatusTooManyRequests, `{"error": {"code": 429, "message": "Resource has been exhausted (e.g. check quota).", "status": "RESOURCE_EXHAUSTED"}}`, ErrRateLimit, true},
+		{http.StatusTooManyRequests, `{"error": {"code": 429, "message": "Quota exceeded for quota metric 'Generate Content API requests per minute' and limit 'GenerateContent request limit per minute'.", "status": "RESOURCE_EXHAUSTED"}}`, ErrRateLimit, true},
+		{http.StatusTooManyRequests, `{"error": {"message": "You have run out of credits. Please check your plan and billing details."}}`, ErrQuota, false},
+		{http.StatusBadRequest, `{"error": {"message": "The response was filtered", "code": "content_filter"}}`, ErrContentFiltered, false},
+		{http.StatusBadRequest, `{"error": {"message": "Invalid model"}}`, ErrBadRequest, false},
+		{http.StatusNotFound, `{"error": "model 'llama9' not found"}`, ErrBadRequest, false},
+		{http.StatusInternalServerError, `{"error": {"message": "The server had an error"}}`, ErrServer, true},
+		{http.StatusBadGateway, "Bad Gateway", ErrServer, true},
+		{http.StatusServiceUnavailable, `{"error": "Model is currently loading"}`, ErrServer, true},
+		{http.StatusOK, "", ErrUnknown, false},
+	}
+	for _, tt := range tests {
+		err := newAPIError("openai", tt.status, http.Header{}, []byte(tt.body))
+		if err.Kind != tt.kind {
+			t.Errorf("%d %s: kind = %v, want %v", tt.status, tt.body, err.Kind, tt.kind)
+		}
+		if err.Retryable() != tt.retryable {
+			t.Errorf("%d %s: retryable = %v, want %v", tt.status, tt.body, err.Retryable(), tt.retryable)
+		}
+		if err.StatusCode != tt.status || err.Provider != "openai" {
+			t.Errorf("%d %s: got %s (%d)", tt.status, tt.body, err.Provider, err.StatusCode)
+		}
+	}
+}
+
+func TestNewAPIErrorRetryAfter(t *testing.T) {
+	header := http.Header{"Retry-After": {"20"}}
+	err := newAPIError("grok", http.StatusTooManyRequests, header, []byte(`{"error": "Too many requests"}`))
+	if err.RetryAfter != 20*time.Second {
+		t.Errorf("RetryAfter = %v, want 20s", err.RetryAfter)
+	}
+	if want := "grok: rate limited (429): Too many requests"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	long := strings.Repeat("x", 400)
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"openai", `{"error": {"message": "Incorrect API key provided: sk-***.", "type": "invalid_request_error", "code": "invalid_api_key"}}`, "Incorrect API key provided: sk-***."},
+		{"gemini", `{"error": {"code": 400, "message": "API key not valid. Please pass a valid API key.", "status": "INVALID_ARGUMENT"}}`, "API key not valid. Please pass a valid API key."},
+		{"huggingface", `{"error": "Model mistralai/Mistral-7B is currently loading", "estimated_time": 20.5}`, "Model mistralai/Mistral-7B is currently loading"},
+		{"ollama", `{"error": "model \"llama9\" not found, try pulling it first"}`, `model "llama9" not found, try pulling it first`},
+		{"grok", `{"code": "Client specified an invalid argument", "error": "Incorrect API key provided: xa***ey."}`, "Incorrect API key provided: xa***ey."},
+		{"top-level message", `{"message": "Unauthorized", "statusCode": 401}`, "Unauthorized"},
+		{"error without message", `{"error": {"code": 500}, "message": "Internal error"}`, "Internal error"},
+		{"plain text", "  Bad Gateway\n", "Bad Gateway"},
+		{"empty", "", ""},
+		{"long body", long, strings.Repeat("x", 300) + "…"},
+		{"long multibyte body", "x" + strings.Repeat("é", 200), "x" + strings.Repeat("é", 149) + "…"},
+	}
+	for _, tt := range tests {
+		if got := errorMessage([]byte(tt.body)); got != tt.want {
+			t.Errorf("%s: errorMessage = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -2,20 +2,102 @@ package ai
 
 import (
 	"fmt"
+	"net/http"
 )
 
-// NewProvider returns the appropriate AI provider implementation
-func NewProvider(name string, apiKey string) (Provider, error) {
+// Options holds the provider settings read from the user's config
+type Options struct {
+	APIKey string
+	// BaseURL overrides the provider's endpoint (the daemon host for Ollama)
+	BaseURL string
+	// Model overrides the provider's default model
+	Model string
+	// Headers are extra HTTP headers sent with every request
+	Headers map[string]string
+	// AuthScheme selects how the API key is sent (bearer, api-key or none)
+	AuthScheme string
+	// ResponseFormat selects how openai-compatible servers are asked for JSON
+	// (json_schema, json_object or none)
+	ResponseFormat string
+
+	// Temperature and TopP are left to the provider default when nil
+	Temperature *float32
+	TopP        *float32
+	// MaxTokens caps the length of the model output; 0 uses the provider default
+	MaxTokens int
+
+	// HTTPClient sends the provider's API requests instead of the shared
+	// client, e.g. to record or replay them (see ReplayTransport)
+	HTTPClient *http.Client
+	// PluginPath, when set, runs the provider as this plugin executable
+	PluginPath string
+	// FakeResponses are the raw model outputs the fake provider returns in order
+	FakeResponses []string
+}
+
+// ProviderInfo describes a provider compiled into CommitFeed
+type ProviderInfo struct {
+	Name        string
+	Description string
+}
+
+// BuiltinProviders lists the providers compiled into CommitFeed
+var BuiltinProviders = []ProviderInfo{
+	{"huggingface", "Hugging Face inference router (the default)"},
+	{"openai", "OpenAI"},
+	{"gemini", "Google Gemini"},
+	{"deepseek", "DeepSeek"},
+	{"grok", "xAI Grok (also \"xai\")"},
+	{"openai-compatible", "Any OpenAI-compatible server, at the configured base_url"},
+	{"ollama", "Local models served by Ollama"},
+	{TemplateProviderName, "Rule-based posts written offline from the commits"},
+}
+
+// modelOr returns the configured model, or def when none is set
+func (o Options) modelOr(def string) string {
+	if o.Model != "" {
+		return o.Model
+	}
+	return def
+}
+
+// client returns the HTTP client API requests are sent with
+func (o Options) client() *http.Client {
+	if o.HTTPClient != nil {
+		return o.HTTPClient
+	}
+	return httpClient
+}
+
+// NewProvider returns the appropriate AI provider implementation. Names that
+// are not built in are looked up as plugins (commitfeed-provider-<name>) on PATH.
+func NewProvider(name string, opts Options) (Provider, error) {
+	if opts.PluginPath != "" {
+		return NewPluginProvider(name, opts.PluginPath, opts), nil
+	}
 	switch name {
 	case "openai":
-		return NewOpenAIProvider(apiKey), nil
+		return NewOpenAIProvider(opts), nil
 	case "gemini":
-		return NewGeminiProvider(apiKey), nil
+		return NewGeminiProvider(opts)
 	case "deepseek":
-		return NewDeepSeekProvider(apiKey), nil
+		return NewDeepSeekProvider(opts), nil
+	case "grok", "xai":
+		return NewGrokProvider(opts), nil
+	case "openai-compatible":
+		return NewOpenAICompatibleProvider(opts)
+	case "ollama":
+		return NewOllamaProvider(opts), nil
+	case TemplateProviderName:
+		return NewTemplateProvider(), nil
+	case FakeProviderName:
+		return NewFakeProvider(opts, opts.FakeResponses...), nil
 	case "huggingface", "default", "":
-		return NewHuggingFaceProvider(apiKey), nil
+		return NewHuggingFaceProvider(opts), nil
 	default:
-		return nil, fmt.Errorf("unknown AI provider: %s", name)
+		if path, ok := LookupPlugin(name, nil); ok {
+			return NewPluginProvider(name, path, opts), nil
+		}
+		return nil, fmt.Errorf("unknown AI provider: %s (and no %s%s plugin on PATH)", name, PluginPrefix, name)
 	}
 }
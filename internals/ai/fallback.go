@@ -0,0 +1,139 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// ChainEntry is a named provider in a fallback chain
+type ChainEntry struct {
+	Name     string
+	Provider Provider
+}
+
+// ChainProvider tries providers in order, moving on to the next one when a
+// provider fails with a retryable or quota error or cannot be reached
+type ChainProvider struct {
+	entries []ChainEntry
+	// OnFallback, if set, is called before switching to the next provider
+	OnFallback func(failed string, err error, next string)
+	// LastResort, if set, is tried after the chain fails on an error another
+	// provider might get past, such as a provider that needs no network. Auth,
+	// bad request and invalid output errors are returned as they are.
+	LastResort *ChainEntry
+}
+
+func NewChainProvider(entries ...ChainEntry) *ChainProvider {
+	return &ChainProvider{entries: entries}
+}
+
+// GeneratePosts returns the posts from the first provider that succeeds.
+// The name of that provider is recorded in GeneratedPosts.Provider.
+func (c *ChainProvider) GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error) {
+	return c.run(ctx, func(p Provider) (*GeneratedPosts, error) {
+		return p.GeneratePosts(ctx, req)
+	})
+}
+
+// StreamPosts is GeneratePosts with streaming for the providers that support it
+func (c *ChainProvider) StreamPosts(ctx context.Context, req Request, onDelta func(key, text string)) (*GeneratedPosts, error) {
+	return c.run(ctx, func(p Provider) (*GeneratedPosts, error) {
+		if sp, ok := p.(StreamingProvider); ok {
+			return sp.StreamPosts(ctx, req, onDelta)
+		}
+		return p.GeneratePosts(ctx, req)
+	})
+}
+
+// VerifyPosts checks the posts with the provider that generated them, moving on
+// to the providers after it on the same errors as GeneratePosts
+func (c *ChainProvider) VerifyPosts(ctx context.Context, req Request, posts *GeneratedPosts) (map[string][]UnsupportedClaim, error) {
+	if c.LastResort != nil && c.LastResort.Name == posts.Provider {
+		return VerifyPosts(ctx, c.LastResort.Provider, req, posts)
+	}
+
+	entries := c.entries
+	for i, entry := range c.entries {
+		if entry.Name == posts.Provider {
+			entries = c.entries[i:]
+			break
+		}
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no AI providers configured")
+	}
+
+	var lastErr error
+	for i, entry := range entries {
+		claims, err := VerifyPosts(ctx, entry.Provider, req, posts)
+		if err == nil {
+			return claims, nil
+		}
+		lastErr = err
+
+		if !shouldFallBack(ctx, err) || i == len(entries)-1 {
+			break
+		}
+		if c.OnFallback != nil {
+			c.OnFallback(entry.Name, err, entries[i+1].Name)
+		}
+	}
+	return nil, lastErr
+}
+
+// run calls generate with each provider in turn until one succeeds or fails
+// for good, and then with the last resort
+func (c *ChainProvider) run(ctx context.Context, generate func(Provider) (*GeneratedPosts, error)) (*GeneratedPosts, error) {
+	if len(c.entries) == 0 {
+		return nil, fmt.Errorf("no AI providers configured")
+	}
+
+	var lastErr error
+	var failed string
+	for i, entry := range c.entries {
+		posts, err := generate(entry.Provider)
+		if err == nil {
+			posts.Provider = entry.Name
+			return posts, nil
+		}
+		lastErr, failed = err, entry.Name
+
+		if !shouldFallBack(ctx, err) || i == len(c.entries)-1 {
+			break
+		}
+		if c.OnFallback != nil {
+			c.OnFallback(entry.Name, err, c.entries[i+1].Name)
+		}
+	}
+
+	if c.LastResort == nil || !shouldFallBack(ctx, lastErr) {
+		return nil, lastErr
+	}
+	if c.OnFallback != nil {
+		c.OnFallback(failed, lastErr, c.LastResort.Name)
+	}
+	posts, err := generate(c.LastResort.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("%w (and %s failed too: %v)", lastErr, c.LastResort.Name, err)
+	}
+	posts.Provider = c.LastResort.Name
+	return posts, nil
+}
+
+// shouldFallBack reports whether another provider might succeed where this one
+// failed: it was rate limited, out of quota, failing server-side or could not
+// be reached at all. Nothing is worth trying once ctx is canceled or expired.
+func shouldFallBack(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable() || apiErr.Kind == ErrQuota
+	}
+	// Connection refused, DNS failures and timeouts of a single provider
+	var netErr net.Error
+	return errors.Is(err, ErrOllamaNotRunning) || errors.As(err, &netErr)
+}
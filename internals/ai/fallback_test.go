@@ -0,0 +1,184 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// stubProvider returns err, or posts when err is nil, and counts its calls
+type stubProvider struct {
+	err   error
+	calls int
+}
+
+func (s *stubProvider) GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	posts := &GeneratedPosts{Posts: map[string]Post{}}
+	for _, p := range req.Platforms {
+		posts.Posts[p] = Post{Platform: p, Text: "Stub post."}
+	}
+	return posts, nil
+}
+
+// offlineChain is a chain of providers with the template provider as its last resort
+func offlineChain(entries ...ChainEntry) *ChainProvider {
+	chain := NewChainProvider(entries...)
+	chain.LastResort = &ChainEntry{Name: TemplateProviderName, Provider: NewTemplateProvider()}
+	return chain
+}
+
+func TestChainKeepsAuthErrorsFromLastResort(t *testing.T) {
+	unauthorized := &APIError{Provider: "openai", Kind: ErrAuth, StatusCode: http.StatusUnauthorized, Message: "Incorrect API key provided"}
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"unauthorized", unauthorized},
+		{"bad request", &APIError{Provider: "openai", Kind: ErrBadRequest, StatusCode: http.StatusBadRequest}},
+		{"content filtered", &APIError{Provider: "openai", Kind: ErrContentFiltered, StatusCode: http.StatusBadRequest}},
+		{"invalid output", &InvalidOutputError{Attempts: 3, Output: "not json"}},
+		{"canceled", context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := offlineChain(ChainEntry{Name: "openai", Provider: &stubProvider{err: tt.err}})
+			chain.OnFallback = func(failed string, err error, next string) {
+				t.Errorf("fell back from %s to %s on %v", failed, next, err)
+			}
+			posts, err := chain.GeneratePosts(context.Background(), replayRequest())
+			if posts != nil {
+				t.Errorf("got offline posts from %s, want the error", posts.Provider)
+			}
+			if err != tt.err {
+				t.Errorf("got %v, want %v unchanged", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestChainLastResortKeepsErrorChain(t *testing.T) {
+	quota := &APIError{Provider: "openai", Kind: ErrQuota, StatusCode: http.StatusTooManyRequests}
+	chain := NewChainProvider(ChainEntry{Name: "openai", Provider: &stubProvider{err: quota}})
+	chain.LastResort = &ChainEntry{Name: TemplateProviderName, Provider: &stubProvider{err: errors.New("no commits")}}
+
+	_, err := chain.GeneratePosts(context.Background(), replayRequest())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr != quota {
+		t.Fatalf("got %v, want it to wrap the quota error", err)
+	}
+}
+
+func TestChainProvider(t *testing.T) {
+	quota := &APIError{Provider: "a", Kind: ErrQuota, StatusCode: http.StatusTooManyRequests}
+	rateLimited := &APIError{Provider: "a", Kind: ErrRateLimit, StatusCode: http.StatusTooManyRequests}
+	server := &APIError{Provider: "b", Kind: ErrServer, StatusCode: http.StatusBadGateway}
+	auth := &APIError{Provider: "a", Kind: ErrAuth, StatusCode: http.StatusUnauthorized}
+	refused := fmt.Errorf("a request failed: %w", &url.Error{Op: "Post", URL: "http://127.0.0.1:1/v1", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}})
+	notRunning := ollamaUnreachable("http://127.0.0.1:11434", errors.New("connection refused"))
+
+	tests := []struct {
+		name      string
+		errs      []error // one per provider a, b, c; nil succeeds
+		provider  string  // provider that wrote the posts, "" for an error
+		err       error   // error the chain returns, matched with errors.Is
+		fallbacks string
+		calls     []int
+	}{
+		{"primary succeeds", []error{nil, nil, nil}, "a", nil, "", []int{1, 0, 0}},
+		{"quota moves on", []error{quota, nil, nil}, "b", nil, "a>b", []int{1, 1, 0}},
+		{"rate limit and server error move on", []error{rateLimited, server, nil}, "c", nil, "a>b b>c", []int{1, 1, 1}},
+		{"connection refused moves on", []error{refused, nil, nil}, "b", nil, "a>b", []int{1, 1, 0}},
+		{"ollama not running moves on", []error{notRunning, nil, nil}, "b", nil, "a>b", []int{1, 1, 0}},
+		{"auth stops", []error{auth, nil, nil}, "", auth, "", []int{1, 0, 0}},
+		{"auth after a fallback stops", []error{quota, auth, nil}, "", auth, "a>b", []int{1, 1, 0}},
+		{"all fail", []error{quota, server, rateLimited}, "", rateLimited, "a>b b>c", []int{1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entries []ChainEntry
+			var stubs []*stubProvider
+			for i, err := range tt.errs {
+				stub := &stubProvider{err: err}
+				stubs = append(stubs, stub)
+				entries = append(entries, ChainEntry{Name: string(rune('a' + i)), Provider: stub})
+			}
+			chain := NewChainProvider(entries...)
+			var fallbacks []string
+			chain.OnFallback = func(failed string, err error, next string) {
+				fallbacks = append(fallbacks, failed+">"+next)
+			}
+
+			posts, err := chain.GeneratePosts(context.Background(), replayRequest())
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("got %v, want %v", err, tt.err)
+				}
+			} else if err != nil {
+				t.Fatalf("GeneratePosts: %v", err)
+			} else if posts.Provider != tt.provider {
+				t.Errorf("posts.Provider = %q, want %q", posts.Provider, tt.provider)
+			}
+			if got := strings.Join(fallbacks, " "); got != tt.fallbacks {
+				t.Errorf("fallbacks = %q, want %q", got, tt.fallbacks)
+			}
+			for i, stub := range stubs {
+				if stub.calls != tt.calls[i] {
+					t.Errorf("provider %s called %d times, want %d", entries[i].Name, stub.calls, tt.calls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestChainLastResort(t *testing.T) {
+	quota := &APIError{Provider: "a", Kind: ErrQuota, StatusCode: http.StatusTooManyRequests}
+	chain := offlineChain(ChainEntry{Name: "a", Provider: &stubProvider{err: quota}})
+	var fallbacks []string
+	chain.OnFallback = func(failed string, err error, next string) {
+		fallbacks = append(fallbacks, failed+">"+next)
+	}
+
+	posts, err := chain.GeneratePosts(context.Background(), replayRequest())
+	if err != nil {
+		t.Fatalf("GeneratePosts: %v", err)
+	}
+	if posts.Provider != TemplateProviderName || posts.Get("linkedin") == "" {
+		t.Errorf("got %q posts from %q, want offline posts", posts.Get("linkedin"), posts.Provider)
+	}
+	if got := strings.Join(fallbacks, " "); got != "a>"+TemplateProviderName {
+		t.Errorf("fallbacks = %q", got)
+	}
+}
+
+func TestChainStopsWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	// The provider fails with a network error because the run was canceled
+	first := &cancelingProvider{cancel: cancel}
+	second := &stubProvider{}
+	chain := offlineChain(ChainEntry{Name: "a", Provider: first}, ChainEntry{Name: "b", Provider: second})
+
+	if _, err := chain.GeneratePosts(ctx, replayRequest()); err == nil {
+		t.Fatal("got posts, want the error")
+	}
+	if second.calls != 0 {
+		t.Error("fell back after the run was canceled")
+	}
+}
+
+type cancelingProvider struct {
+	cancel context.CancelFunc
+}
+
+func (p *cancelingProvider) GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error) {
+	p.cancel()
+	return nil, &url.Error{Op: "Post", URL: "http://127.0.0.1:1/v1", Err: ctx.Err()}
+}
@@ -0,0 +1,160 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const verifyInstructions = `You are fact-checking social media posts written about a software project. The Git commits below are the only source of truth for what changed; the project context is background only.
+
+For each post, list every claim the commits do not support: features, fixes, numbers (e.g. "10x faster"), benchmarks, dates, compatibility or user impact that appear nowhere in the commits. Reasonable paraphrases, summaries and general enthusiasm are fine — do not flag them.
+`
+
+// UnsupportedClaim is a statement in a post that the commits do not back up
+type UnsupportedClaim struct {
+	Claim  string `json:"claim"`
+	Reason string `json:"reason"`
+}
+
+// Verifier is implemented by providers that check generated posts against the commits
+type Verifier interface {
+	VerifyPosts(ctx context.Context, req Request, posts *GeneratedPosts) (map[string][]UnsupportedClaim, error)
+}
+
+// VerifyPosts asks p which claims in the posts the commits of req do not
+// support. The result is keyed like posts.Posts and only holds posts with
+// unsupported claims.
+func VerifyPosts(ctx context.Context, p Provider, req Request, posts *GeneratedPosts) (map[string][]UnsupportedClaim, error) {
+	if v, ok := p.(Verifier); ok {
+		return v.VerifyPosts(ctx, req, posts)
+	}
+	c, ok := p.(chatCompleter)
+	if !ok {
+		return nil, fmt.Errorf("provider %T cannot verify posts", p)
+	}
+	return verifyPosts(ctx, c, req, posts)
+}
+
+// verifyPosts sends the posts and the commits back to the model in a single request
+func verifyPosts(ctx context.Context, c chatCompleter, req Request, posts *GeneratedPosts) (map[string][]UnsupportedClaim, error) {
+	var keys []string
+	for _, t := range req.Targets() {
+		if _, ok := posts.Posts[t.Key()]; ok {
+			keys = append(keys, t.Key())
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(verifyInstructions)
+	if req.ProjectContext != "" {
+		sb.WriteString("\n--- Project Context ---\n")
+		sb.WriteString(req.ProjectContext)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n--- Posts ---\n")
+	for _, key := range keys {
+		fmt.Fprintf(&sb, "[%s]\n%s\n\n", key, posts.Posts[key].Text)
+	}
+
+	// The commits go last and are cut to what fits in the model's context
+	model, budget := promptBudget(c)
+	lines := commitLines(req.Commits)
+	chunks := chunkItems(model, lines, max(256, budget-EstimateTokens(model, sb.String())-256))
+	sb.WriteString("--- Commit Messages ---\n")
+	if len(chunks) > 0 {
+		for _, line := range chunks[0] {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+		if rest := len(lines) - len(chunks[0]); rest > 0 {
+			fmt.Fprintf(&sb, "(%d more commits are not shown; only flag claims that are clearly invented)\n", rest)
+		}
+	}
+
+	sb.WriteString("\nRespond with ONLY a JSON object in exactly this shape, with an empty list for a post whose claims are all supported:\n")
+	sb.WriteString(`{"posts": {`)
+	for i, key := range keys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, `"%s": [{"claim": "<the claim, quoted from the post>", "reason": "<why the commits do not support it>"}]`, key)
+	}
+	sb.WriteString("}}\n")
+
+	out, err := c.complete(ctx, chatRequest{
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: sb.String()},
+		},
+		Schema: claimsSchema(keys),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify posts: %w", err)
+	}
+	return parseClaims(out, keys)
+}
+
+// claimsSchema describes the {"posts": {"<key>": [{"claim": ..., "reason": ...}]}} verification result
+func claimsSchema(keys []string) *jsonSchema {
+	closed := false
+	claim := &jsonSchema{
+		Type: "object",
+		Properties: map[string]*jsonSchema{
+			"claim":  {Type: "string"},
+			"reason": {Type: "string"},
+		},
+		Required:             []string{"claim", "reason"},
+		AdditionalProperties: &closed,
+	}
+	posts := &jsonSchema{
+		Type:                 "object",
+		Properties:           make(map[string]*jsonSchema, len(keys)),
+		Required:             keys,
+		AdditionalProperties: &closed,
+	}
+	for _, key := range keys {
+		posts.Properties[key] = &jsonSchema{Type: "array", Items: claim}
+	}
+	return &jsonSchema{
+		Type:                 "object",
+		Properties:           map[string]*jsonSchema{"posts": posts},
+		Required:             []string{"posts"},
+		AdditionalProperties: &closed,
+	}
+}
+
+// parseClaims reads the verification result, keeping the posts with claims
+func parseClaims(text string, keys []string) (map[string][]UnsupportedClaim, error) {
+	raw := extractJSON(text)
+	if raw == "" {
+		return nil, fmt.Errorf("verification response does not contain a JSON object")
+	}
+	var parsed struct {
+		Posts map[string][]UnsupportedClaim `json:"posts"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("verification response is not valid JSON: %v", err)
+	}
+
+	values := make(map[string][]UnsupportedClaim, len(parsed.Posts))
+	for k, v := range parsed.Posts {
+		values[ParseTarget(k).Key()] = v
+	}
+
+	claims := make(map[string][]UnsupportedClaim)
+	for _, key := range keys {
+		for _, claim := range values[key] {
+			claim.Claim = strings.TrimSpace(claim.Claim)
+			claim.Reason = strings.TrimSpace(claim.Reason)
+			if claim.Claim != "" {
+				claims[key] = append(claims[key], claim)
+			}
+		}
+	}
+	return claims, nil
+}
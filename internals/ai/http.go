@@ -0,0 +1,91 @@
+package ai
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	maxHTTPRetries = 3
+	retryBaseDelay = time.Second
+	retryMaxDelay  = 30 * time.Second
+)
+
+// httpClient is shared by the hand-rolled providers. Requests are bounded by
+// their context; the client timeout is only a backstop for callers without one.
+var httpClient = &http.Client{
+	Timeout:   5 * time.Minute,
+	Transport: &retryTransport{base: http.DefaultTransport},
+}
+
+// retryTransport retries rate-limited (429) and server (5xx) responses with
+// jittered exponential backoff, honoring Retry-After when the provider sends it.
+// Exhausted quotas are not retried since waiting will not help.
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || attempt >= maxHTTPRetries || !shouldRetry(resp) {
+			return resp, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil // body cannot be replayed
+		}
+
+		delay := backoff(attempt, parseRetryAfter(resp.Header.Get("Retry-After")))
+		if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < delay {
+			return resp, nil // not enough time left to wait
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// shouldRetry reports whether a response is worth retrying. 429 bodies are
+// inspected (and restored) so that quota errors are returned immediately.
+func shouldRetry(resp *http.Response) bool {
+	if resp.StatusCode >= 500 {
+		return true
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	return !isQuotaError(resp.StatusCode, strings.ToLower(string(data)))
+}
+
+// backoff returns the wait before the next attempt: the provider's Retry-After
+// if given, otherwise exponential backoff with jitter
+func backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, retryMaxDelay)
+	}
+	delay := min(retryBaseDelay<<attempt, retryMaxDelay)
+	return delay/2 + rand.N(delay/2+1)
+}
@@ -0,0 +1,98 @@
+package ai
+
+import "strings"
+
+// languageNames maps common ISO 639-1 language codes to the names used in prompts and output
+var languageNames = map[string]string{
+	"ar": "Arabic",
+	"de": "German",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"hi": "Hindi",
+	"id": "Indonesian",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"nl": "Dutch",
+	"pl": "Polish",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"sv": "Swedish",
+	"tr": "Turkish",
+	"uk": "Ukrainian",
+	"zh": "Chinese",
+}
+
+// NormalizeLanguage returns the canonical form of a language: a lower-case code
+// such as "fr" or "pt-br". Known language names are accepted too ("French" -> "fr").
+func NormalizeLanguage(lang string) string {
+	l := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(lang)), "_", "-")
+	for code, name := range languageNames {
+		if strings.EqualFold(l, name) {
+			return code
+		}
+	}
+	return l
+}
+
+// NormalizeLanguages normalizes a list of languages and drops empty entries and duplicates
+func NormalizeLanguages(langs []string) []string {
+	seen := make(map[string]bool, len(langs))
+	var normalized []string
+	for _, l := range langs {
+		l = NormalizeLanguage(l)
+		if l == "" || seen[l] {
+			continue
+		}
+		seen[l] = true
+		normalized = append(normalized, l)
+	}
+	return normalized
+}
+
+// LanguageName returns the human-readable name of a language (e.g. "pt-br" ->
+// "Portuguese (BR)"), or the code itself if the language is not known
+func LanguageName(lang string) string {
+	l := NormalizeLanguage(lang)
+	base, region, _ := strings.Cut(l, "-")
+	name, ok := languageNames[base]
+	if !ok {
+		return l
+	}
+	if region != "" {
+		return name + " (" + strings.ToUpper(region) + ")"
+	}
+	return name
+}
+
+// Target is one post a request asks for: a platform, optionally in a given language
+type Target struct {
+	Platform string
+	// Lang is the language code of the post, or "" when no language was requested
+	Lang string
+}
+
+// Key identifies the target's post in GeneratedPosts.Posts and in the model
+// output: the platform name, followed by ":<lang>" for a language variant
+// (e.g. "linkedin" or "linkedin:fr")
+func (t Target) Key() string {
+	if t.Lang == "" {
+		return t.Platform
+	}
+	return t.Platform + ":" + t.Lang
+}
+
+// Label returns the human-readable label for the target, e.g. "LinkedIn (French)"
+func (t Target) Label() string {
+	if t.Lang == "" {
+		return PlatformLabel(t.Platform)
+	}
+	return PlatformLabel(t.Platform) + " (" + LanguageName(t.Lang) + ")"
+}
+
+// ParseTarget parses a post key such as "X:FR" into its canonical target
+func ParseTarget(key string) Target {
+	platform, lang, _ := strings.Cut(key, ":")
+	return Target{Platform: NormalizePlatform(platform), Lang: NormalizeLanguage(lang)}
+}
@@ -0,0 +1,287 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// DefaultShortenAttempts is how often an over-long post is sent back to the
+// model for shortening before it is truncated
+const DefaultShortenAttempts = 2
+
+// shortenedURLLength is the length X (t.co) and Mastodon count for every link
+const shortenedURLLength = 23
+
+// urlPattern matches links, leaving out trailing punctuation
+var urlPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s]*[^\s.,:;!?'")\]]`)
+
+// lengthRule is how a platform measures posts and the most it accepts
+type lengthRule struct {
+	limit int
+	count func(string) int
+	// unit describes what count measures, for prompts and messages
+	unit string
+}
+
+// lengthRules holds the platforms that limit post length
+var lengthRules = map[string]lengthRule{
+	"twitter":  {limit: 280, count: twitterLength, unit: "characters (links count as 23, emoji as 2)"},
+	"bluesky":  {limit: 300, count: uniseg.GraphemeClusterCount, unit: "characters"},
+	"mastodon": {limit: 500, count: mastodonLength, unit: "characters (links count as 23)"},
+	"linkedin": {limit: 3000, count: utf8.RuneCountInString, unit: "characters"},
+}
+
+// CharLimit returns the most characters a post may have on a platform, or 0 if there is no limit
+func CharLimit(platform string) int {
+	return lengthRules[NormalizePlatform(platform)].limit
+}
+
+// PostLength measures text the way the platform does, e.g. with weighted counting on X
+func PostLength(platform, text string) int {
+	if rule, ok := lengthRules[NormalizePlatform(platform)]; ok {
+		return rule.count(text)
+	}
+	return utf8.RuneCountInString(text)
+}
+
+// twitterLength implements X's weighted character count: links count as 23,
+// emoji as 2, and characters outside the Latin-centric ranges as 2
+func twitterLength(text string) int {
+	n, last := 0, 0
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		n += weightedLength(text[last:loc[0]]) + shortenedURLLength
+		last = loc[1]
+	}
+	return n + weightedLength(text[last:])
+}
+
+// weightedLength sums X's weights over the characters of text
+func weightedLength(text string) int {
+	n := 0
+	state := -1
+	var cluster string
+	for len(text) > 0 {
+		cluster, text, _, state = uniseg.FirstGraphemeClusterInString(text, state)
+		if isEmoji(cluster) {
+			n += 2
+			continue
+		}
+		for _, r := range cluster {
+			n += twitterWeight(r)
+		}
+	}
+	return n
+}
+
+// twitterWeight is the weight X gives to a single character
+func twitterWeight(r rune) int {
+	switch {
+	case r <= 0x10FF, r >= 0x2000 && r <= 0x200D, r >= 0x2010 && r <= 0x201F, r >= 0x2032 && r <= 0x2037:
+		return 1
+	}
+	return 2
+}
+
+// isEmoji reports whether a grapheme cluster is an emoji, including
+// sequences such as flags, keycaps and skin tone variants
+func isEmoji(cluster string) bool {
+	for _, r := range cluster {
+		if r >= 0x1F000 || r == 0xFE0F || r == 0x20E3 || (r >= 0x2600 && r <= 0x27BF) {
+			return true
+		}
+	}
+	return false
+}
+
+// mastodonLength counts characters with every link counted as 23
+func mastodonLength(text string) int {
+	n := 0
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		n += shortenedURLLength - utf8.RuneCountInString(text[loc[0]:loc[1]])
+	}
+	return n + utf8.RuneCountInString(text)
+}
+
+// fitPosts makes every post, variant and thread part fit its platform's
+// limit. Thread parts are numbered "1/n" once they fit.
+func fitPosts(ctx context.Context, c chatCompleter, posts *GeneratedPosts, attempts int) error {
+	for key, post := range posts.Posts {
+		p := post.Platform
+		if len(post.Parts) > 0 {
+			parts, err := fitThread(ctx, c, p, post.Parts, attempts)
+			if err != nil {
+				return err
+			}
+			post.Parts = parts
+			post.Text = strings.Join(parts, "\n\n")
+			posts.Posts[key] = post
+			continue
+		}
+		if _, ok := lengthRules[p]; !ok {
+			continue
+		}
+		if len(post.Variants) == 0 {
+			text, err := fitPost(ctx, c, p, post.Text, attempts)
+			if err != nil {
+				return err
+			}
+			post.Text = text
+		}
+		for i, v := range post.Variants {
+			text, err := fitPost(ctx, c, p, v, attempts)
+			if err != nil {
+				return err
+			}
+			post.Variants[i] = text
+			if i == 0 {
+				post.Text = text
+			}
+		}
+		posts.Posts[key] = post
+	}
+	return nil
+}
+
+// fitThread fits each part of a thread, leaving room for its number, and numbers it
+func fitThread(ctx context.Context, c chatCompleter, platform string, parts []string, attempts int) ([]string, error) {
+	numbered := make([]string, len(parts))
+	for i, part := range parts {
+		prefix := fmt.Sprintf("%d/%d ", i+1, len(parts))
+		if rule, ok := lengthRules[platform]; ok {
+			rule.limit -= rule.count(prefix)
+			fitted, err := fitText(ctx, c, platform, part, rule, attempts)
+			if err != nil {
+				return nil, err
+			}
+			part = fitted
+		}
+		numbered[i] = prefix + part
+	}
+	return numbered, nil
+}
+
+// fitPost asks the model to shorten an over-long post up to attempts times,
+// then truncates it at a sentence boundary if it is still too long
+func fitPost(ctx context.Context, c chatCompleter, platform, text string, attempts int) (string, error) {
+	return fitText(ctx, c, platform, text, lengthRules[platform], attempts)
+}
+
+// fitText is fitPost against an explicit rule
+func fitText(ctx context.Context, c chatCompleter, platform, text string, rule lengthRule, attempts int) (string, error) {
+	for i := 0; i < attempts && rule.count(text) > rule.limit; i++ {
+		shorter, err := shortenPost(ctx, c, platform, text, rule)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			// A failed shortening is not worth failing the generation for
+			break
+		}
+		if shorter != "" {
+			text = shorter
+		}
+	}
+	if rule.count(text) > rule.limit {
+		text = truncatePost(text, rule)
+	}
+	return text, nil
+}
+
+// shortenPost asks the model for a shorter version of a post
+func shortenPost(ctx context.Context, c chatCompleter, platform, text string, rule lengthRule) (string, error) {
+	prompt := fmt.Sprintf(`This %s post is %d %s long, but the limit is %d. Rewrite it to fit within the limit, with some room to spare.
+Keep its language, the key message, tone, links and the most relevant hashtags. Drop details before dropping the point.
+
+--- Post ---
+%s
+
+Respond with ONLY a JSON object in exactly this shape: {"post": "<shortened post>"}`,
+		PlatformLabel(platform), rule.count(text), rule.unit, rule.limit, text)
+
+	closed := false
+	out, err := c.complete(ctx, chatRequest{
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: prompt},
+		},
+		Schema: &jsonSchema{
+			Type:                 "object",
+			Properties:           map[string]*jsonSchema{"post": {Type: "string"}},
+			Required:             []string{"post"},
+			AdditionalProperties: &closed,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var parsed struct {
+		Post string `json:"post"`
+	}
+	if err := json.Unmarshal([]byte(extractJSON(out)), &parsed); err != nil {
+		return "", fmt.Errorf("shortened post is not valid JSON: %v", err)
+	}
+	return strings.TrimSpace(parsed.Post), nil
+}
+
+// truncatePost cuts text to the rule's limit, at the end of the last sentence
+// that fits, or at a word boundary with an ellipsis if not even one sentence fits
+func truncatePost(text string, rule lengthRule) string {
+	runes := []rune(text)
+	best := -1
+	for i, r := range runes {
+		if !isSentenceEnd(r) {
+			continue
+		}
+		// "v1.2" or "e.g." mid-word is not the end of a sentence
+		if r != '\n' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			continue
+		}
+		if rule.count(string(runes[:i+1])) > rule.limit {
+			break
+		}
+		best = i + 1
+	}
+	if best > 0 {
+		return strings.TrimSpace(string(runes[:best]))
+	}
+
+	cut := ""
+	for i, r := range runes {
+		if !unicode.IsSpace(r) {
+			continue
+		}
+		candidate := strings.TrimRight(string(runes[:i]), " \t\n,;:-") + "…"
+		if rule.count(candidate) > rule.limit {
+			break
+		}
+		cut = candidate
+	}
+	if cut != "" {
+		return cut
+	}
+
+	// A single word longer than the limit: cut anywhere
+	for n := len(runes); n > 0; n-- {
+		if candidate := string(runes[:n-1]) + "…"; rule.count(candidate) <= rule.limit {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// isSentenceEnd reports whether r can end a sentence
+func isSentenceEnd(r rune) bool {
+	switch r {
+	case '.', '!', '?', '…', '\n':
+		return true
+	}
+	return false
+}
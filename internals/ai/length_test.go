@@ -0,0 +1,109 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPostLength(t *testing.T) {
+	tests := []struct {
+		platform string
+		text     string
+		want     int
+	}{
+		{"twitter", "Widgetly 2.0 is out!", 20},
+		{"twitter", "Read more: https://example.com/blog/2025/03/widgetly-csv-export", 11 + 23},
+		{"twitter", "Docs at https://example.com/docs.", 8 + 23 + 1},
+		{"twitter", "Two links https://a.example http://b.example/x", 10 + 23 + 1 + 23},
+		{"twitter", "Ship it 🚀", 8 + 2},
+		{"twitter", "👍🏽", 2},
+		{"twitter", "👩‍👩‍👧‍👦", 2},
+		{"twitter", "🇯🇵", 2},
+		{"twitter", "1️⃣", 2},
+		{"twitter", "你好世界", 8},
+		{"twitter", "日本語 and English", 6 + 12},
+		{"twitter", "café — “quoted”", 15},
+		{"X", "alias", 5},
+		{"bluesky", "👩‍👩‍👧‍👦", 1},
+		{"bluesky", "é", 1},
+		{"bluesky", "你好世界", 4},
+		{"bluesky", "https://example.com/a/very/long/path", 36},
+		{"mastodon", "See https://example.com/a/very/long/path", 4 + 23},
+		{"mastodon", "你好 🚀", 4},
+		{"linkedin", "é", 2},
+		{"linkedin", "https://example.com", 19},
+		{"reddit", "no limit here", 13},
+	}
+	for _, tt := range tests {
+		if got := PostLength(tt.platform, tt.text); got != tt.want {
+			t.Errorf("PostLength(%q, %q) = %d, want %d", tt.platform, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFitPostAtLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		text     string
+		want     string
+	}{
+		{"ascii at limit", "twitter", strings.Repeat("a", 280), strings.Repeat("a", 280)},
+		{"cjk at limit", "twitter", strings.Repeat("字", 140), strings.Repeat("字", 140)},
+		{"emoji at limit", "bluesky", strings.Repeat("👩‍👩‍👧‍👦", 300), strings.Repeat("👩‍👩‍👧‍👦", 300)},
+		// X weighs the ellipsis as 2
+		{"one over", "twitter", strings.Repeat("a", 281), strings.Repeat("a", 278) + "…"},
+		{"cjk one over", "twitter", strings.Repeat("字", 141), strings.Repeat("字", 139) + "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Without shortening attempts the model is never called
+			got, err := fitPost(context.Background(), nil, tt.platform, tt.text, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q (%d), want %q", got, PostLength(tt.platform, got), tt.want)
+			}
+			if n := PostLength(tt.platform, got); n > CharLimit(tt.platform) {
+				t.Errorf("fitted post is %d characters, over the limit of %d", n, CharLimit(tt.platform))
+			}
+		})
+	}
+}
+
+func TestTruncatePost(t *testing.T) {
+	runes := lengthRule{limit: 20, count: utf8.RuneCountInString}
+	twitter := lengthRules["twitter"]
+	twitter.limit = 10
+
+	tests := []struct {
+		name string
+		rule lengthRule
+		text string
+		want string
+	}{
+		{"last sentence that fits", runes, "First one. Second sentence is long.", "First one."},
+		{"several sentences", runes, "One. Two! Three? Four is too long.", "One. Two! Three?"},
+		{"line break ends a sentence", runes, "Release notes\nEverything else follows", "Release notes"},
+		{"no sentence boundary", runes, "alpha beta gamma delta epsilon", "alpha beta gamma…"},
+		{"version number is not a sentence end", runes, "v1.2 is out and it is great", "v1.2 is out and it…"},
+		{"trailing punctuation before the ellipsis", runes, "alpha, beta, gamma, delta", "alpha, beta, gamma…"},
+		{"single long word", runes, "abcdefghijklmnopqrstuvwxyz", "abcdefghijklmnopqrs…"},
+		{"weighted emoji", twitter, "Ship it 🚀. More to come.", "Ship it…"},
+		{"weighted cjk", twitter, "你好世界你好世界", "你好世界…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncatePost(tt.text, tt.rule)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if n := tt.rule.count(got); n > tt.rule.limit {
+				t.Errorf("%q is %d long, over the limit of %d", got, n, tt.rule.limit)
+			}
+		})
+	}
+}
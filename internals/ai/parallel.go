@@ -0,0 +1,102 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultConcurrency is how many platforms GeneratePerPlatform generates at once
+const DefaultConcurrency = 3
+
+// PlatformError lists the platforms whose posts could not be generated when
+// each platform was generated on its own
+type PlatformError struct {
+	// Platforms lists the failed platforms in request order
+	Platforms []string
+	// Errors maps each failed platform to its error
+	Errors map[string]error
+}
+
+func (e *PlatformError) Error() string {
+	msgs := make([]string, 0, len(e.Platforms))
+	for _, p := range e.Platforms {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", PlatformLabel(p), e.Errors[p]))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap lets errors.Is and errors.As see the errors of the platforms
+func (e *PlatformError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Platforms))
+	for _, p := range e.Platforms {
+		errs = append(errs, e.Errors[p])
+	}
+	return errs
+}
+
+// GeneratePerPlatform generates the posts of each platform with a request of
+// its own, so that every prompt carries only that platform's guidelines and a
+// long-form post cannot crowd out a short one. Up to limit requests run at
+// once. The posts are merged into one result, whose Provider is the one that
+// wrote the first platform's posts. Platforms may fall back to different
+// providers, so each post records its own (see PostProvider). When only some
+// platforms fail, the posts of the others are returned along with a
+// *PlatformError; when all of them fail, only the *PlatformError is.
+func GeneratePerPlatform(ctx context.Context, p Provider, req Request, limit int) (*GeneratedPosts, error) {
+	platforms := NormalizePlatforms(req.Platforms)
+	if len(platforms) < 2 {
+		return p.GeneratePosts(ctx, req)
+	}
+
+	meter := &UsageMeter{}
+	ctx = WithUsageMeter(ctx, meter)
+	results := make([]*GeneratedPosts, len(platforms))
+	errs := make([]error, len(platforms))
+
+	var g errgroup.Group
+	g.SetLimit(max(limit, 1))
+	for i, platform := range platforms {
+		g.Go(func() error {
+			single := req
+			single.Platforms = []string{platform}
+			results[i], errs[i] = p.GeneratePosts(ctx, single)
+			// A failed platform must not cancel the others, so it is not reported here
+			return nil
+		})
+	}
+	g.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	merged := &GeneratedPosts{Posts: make(map[string]Post)}
+	failed := &PlatformError{Errors: make(map[string]error)}
+	for i, platform := range platforms {
+		if errs[i] != nil {
+			failed.Platforms = append(failed.Platforms, platform)
+			failed.Errors[platform] = errs[i]
+			continue
+		}
+		if merged.Provider == "" {
+			merged.Provider = results[i].Provider
+		}
+		for key, post := range results[i].Posts {
+			post.Provider = results[i].Provider
+			merged.Posts[key] = post
+		}
+	}
+	merged.Usage = meter.Usage()
+
+	switch len(failed.Platforms) {
+	case 0:
+		return merged, nil
+	case len(platforms):
+		return nil, failed
+	default:
+		return merged, failed
+	}
+}
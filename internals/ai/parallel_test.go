@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// platformFailer fails the platforms in fail and has the fake provider write
+// the posts of the others
+type platformFailer struct {
+	fail map[string]error
+}
+
+func (p *platformFailer) GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error) {
+	if err := p.fail[req.Platforms[0]]; err != nil {
+		return nil, err
+	}
+	return NewFakeProvider(Options{}).GeneratePosts(ctx, req)
+}
+
+func TestGeneratePerPlatformKeepsSuccesses(t *testing.T) {
+	auth := &APIError{Provider: "primary", Kind: ErrAuth, StatusCode: http.StatusUnauthorized}
+	quota := &APIError{Provider: "primary", Kind: ErrQuota, StatusCode: http.StatusTooManyRequests}
+	chain := NewChainProvider(
+		ChainEntry{Name: "primary", Provider: &platformFailer{fail: map[string]error{"mastodon": auth, "twitter": quota}}},
+		ChainEntry{Name: "backup", Provider: &platformFailer{fail: map[string]error{"mastodon": auth}}},
+	)
+	req := replayRequest()
+	req.Platforms = []string{"linkedin", "mastodon", "twitter", "bluesky"}
+
+	posts, err := GeneratePerPlatform(context.Background(), chain, req, DefaultConcurrency)
+
+	var failed *PlatformError
+	if !errors.As(err, &failed) {
+		t.Fatalf("got %v, want a PlatformError", err)
+	}
+	if !reflect.DeepEqual(failed.Platforms, []string{"mastodon"}) {
+		t.Errorf("failed platforms = %q, want mastodon", failed.Platforms)
+	}
+	if !errors.Is(err, auth) {
+		t.Errorf("PlatformError does not wrap the platform's error: %v", err)
+	}
+	if got, want := err.Error(), "Mastodon: "+auth.Error(); got != want {
+		t.Errorf("error = %q, want %q", got, want)
+	}
+
+	if posts == nil {
+		t.Fatal("no posts for the platforms that succeeded")
+	}
+	for platform, provider := range map[string]string{"linkedin": "primary", "twitter": "backup", "bluesky": "primary"} {
+		if posts.Get(platform) == "" {
+			t.Errorf("no %s post", platform)
+		}
+		if got := posts.PostProvider(platform); got != provider {
+			t.Errorf("%s post written by %q, want %q", platform, got, provider)
+		}
+	}
+	if _, ok := posts.Posts["mastodon"]; ok {
+		t.Error("got a post for the failed platform")
+	}
+	if posts.Provider != "primary" {
+		t.Errorf("Provider = %q, want the first platform's", posts.Provider)
+	}
+}
+
+func TestGeneratePerPlatformAllFail(t *testing.T) {
+	server := &APIError{Provider: "primary", Kind: ErrServer, StatusCode: http.StatusBadGateway}
+	p := &platformFailer{fail: map[string]error{"linkedin": server, "twitter": server}}
+
+	posts, err := GeneratePerPlatform(context.Background(), p, replayRequest(), 1)
+	var failed *PlatformError
+	if posts != nil || !errors.As(err, &failed) {
+		t.Fatalf("got %v, %v, want only a PlatformError", posts, err)
+	}
+	if !reflect.DeepEqual(failed.Platforms, []string{"linkedin", "twitter"}) {
+		t.Errorf("failed platforms = %q", failed.Platforms)
+	}
+}
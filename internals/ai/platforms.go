@@ -0,0 +1,74 @@
+package ai
+
+import "strings"
+
+// platformAliases maps alternative platform names to their canonical form
+var platformAliases = map[string]string{
+	"x":      "twitter",
+	"dev.to": "devto",
+	"bsky":   "bluesky",
+}
+
+// platformLabels maps canonical platform names to the labels used in prompts and output
+var platformLabels = map[string]string{
+	"linkedin": "LinkedIn",
+	"twitter":  "Twitter",
+	"mastodon": "Mastodon",
+	"bluesky":  "Bluesky",
+	"devto":    "Dev.to",
+	"reddit":   "Reddit",
+}
+
+// threadPlatforms are the platforms where posts can be published as a thread of replies
+var threadPlatforms = map[string]bool{
+	"twitter":  true,
+	"bluesky":  true,
+	"mastodon": true,
+}
+
+// NormalizePlatform returns the canonical name for a platform (e.g. "X" -> "twitter")
+func NormalizePlatform(platform string) string {
+	p := strings.ToLower(strings.TrimSpace(platform))
+	if canonical, ok := platformAliases[p]; ok {
+		return canonical
+	}
+	return p
+}
+
+// NormalizePlatforms normalizes a list of platforms and drops empty entries and duplicates
+func NormalizePlatforms(platforms []string) []string {
+	seen := make(map[string]bool, len(platforms))
+	var normalized []string
+	for _, p := range platforms {
+		p = NormalizePlatform(p)
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		normalized = append(normalized, p)
+	}
+	return normalized
+}
+
+// PlatformLabel returns the human-readable label for a platform
+func PlatformLabel(platform string) string {
+	p := NormalizePlatform(platform)
+	if label, ok := platformLabels[p]; ok {
+		return label
+	}
+	if p == "" {
+		return p
+	}
+	return strings.ToUpper(p[:1]) + p[1:]
+}
+
+// KnownPlatform reports whether CommitFeed writes and publishes posts for a platform
+func KnownPlatform(platform string) bool {
+	_, ok := platformLabels[NormalizePlatform(platform)]
+	return ok
+}
+
+// SupportsThreads reports whether a platform can publish a post as a thread
+func SupportsThreads(platform string) bool {
+	return threadPlatforms[NormalizePlatform(platform)]
+}
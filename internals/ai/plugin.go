@@ -0,0 +1,348 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// PluginPrefix starts the name of every plugin executable, followed by the provider name
+	PluginPrefix = "commitfeed-provider-"
+	// PluginProtocolVersion is the version of the JSON protocol spoken with plugins
+	PluginProtocolVersion = 1
+
+	// defaultPluginTimeout bounds a plugin run when the context has no deadline
+	defaultPluginTimeout = 5 * time.Minute
+	// pluginWaitDelay is how long a killed plugin gets to close its output
+	pluginWaitDelay = 2 * time.Second
+	// pluginStderrLines is how much of a failed plugin's stderr is shown
+	pluginStderrLines = 5
+)
+
+// Plugin request types
+const (
+	PluginGenerate = "generate"
+	PluginDescribe = "describe"
+)
+
+// PluginRequest is written to a plugin's stdin as a single JSON object. A
+// "describe" request carries only the version, type and provider name.
+type PluginRequest struct {
+	Version  int    `json:"version"`
+	Type     string `json:"type"`
+	Provider string `json:"provider"`
+
+	Commits        []PluginCommit `json:"commits,omitempty"`
+	Platforms      []string       `json:"platforms,omitempty"`
+	Targets        []PluginTarget `json:"targets,omitempty"`
+	ProjectContext string         `json:"project_context,omitempty"`
+	// Prompt is the prompt CommitFeed would send a model, for plugins that
+	// only relay it; its output format instructions match PluginResponse.Posts
+	Prompt  string        `json:"prompt,omitempty"`
+	Options PluginOptions `json:"options"`
+}
+
+// PluginCommit is a commit as sent to plugins
+type PluginCommit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Message string    `json:"message"`
+}
+
+// PluginTarget is a post the plugin has to write
+type PluginTarget struct {
+	// Key is the key of the post in PluginResponse.Posts, e.g. "linkedin:fr"
+	Key      string `json:"key"`
+	Platform string `json:"platform"`
+	Lang     string `json:"lang,omitempty"`
+	// Limit is the platform's length limit, 0 when it has none
+	Limit int `json:"limit"`
+	// Thread is set when the post is a thread, answered with a list of parts
+	Thread bool `json:"thread,omitempty"`
+}
+
+// PluginOptions are the provider settings from the user's config
+type PluginOptions struct {
+	Model       string            `json:"model,omitempty"`
+	BaseURL     string            `json:"base_url,omitempty"`
+	APIKey      string            `json:"api_key,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Temperature *float32          `json:"temperature,omitempty"`
+	TopP        *float32          `json:"top_p,omitempty"`
+	MaxTokens   int               `json:"max_tokens,omitempty"`
+}
+
+// PluginResponse is read from a plugin's stdout as a single JSON object
+type PluginResponse struct {
+	Version int `json:"version"`
+	// Posts maps target keys to the post text, or to a list of parts for threads
+	Posts map[string]any `json:"posts,omitempty"`
+	// Model and Usage, if given, are reported and recorded in the usage ledger
+	Model string       `json:"model,omitempty"`
+	Usage *PluginUsage `json:"usage,omitempty"`
+	// Description answers a "describe" request
+	Description string       `json:"description,omitempty"`
+	Error       *PluginError `json:"error,omitempty"`
+}
+
+// PluginUsage is the token usage a plugin reports
+type PluginUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// PluginError reports a failure. Kind is one of "auth", "rate_limit",
+// "quota", "content_filtered", "bad_request" or "server", and decides
+// whether fallback providers are tried, as for the built-in providers.
+type PluginError struct {
+	Kind    string `json:"kind,omitempty"`
+	Message string `json:"message"`
+	// RetryAfter is the wait the plugin's backend asked for, in seconds
+	RetryAfter float64 `json:"retry_after,omitempty"`
+}
+
+// pluginErrorKinds maps PluginError kinds to error kinds
+var pluginErrorKinds = map[string]ErrorKind{
+	"auth":             ErrAuth,
+	"rate_limit":       ErrRateLimit,
+	"quota":            ErrQuota,
+	"content_filtered": ErrContentFiltered,
+	"bad_request":      ErrBadRequest,
+	"server":           ErrServer,
+}
+
+// PluginInfo is a plugin provider found on PATH or in the config
+type PluginInfo struct {
+	Name string
+	Path string
+	// Configured is set for plugins listed in the config rather than found on PATH
+	Configured bool
+}
+
+// FindPlugins lists the plugin providers on PATH and in configured, which maps
+// provider names to executables and takes precedence over PATH
+func FindPlugins(configured map[string]string) []PluginInfo {
+	found := make(map[string]PluginInfo)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutPrefix(e.Name(), PluginPrefix)
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			if !ok || name == "" || e.IsDir() {
+				continue
+			}
+			if _, seen := found[name]; seen {
+				continue // the first on PATH wins, as when running it
+			}
+			path := filepath.Join(dir, e.Name())
+			if _, err := exec.LookPath(path); err == nil {
+				found[name] = PluginInfo{Name: name, Path: path}
+			}
+		}
+	}
+	for name, path := range configured {
+		found[name] = PluginInfo{Name: name, Path: path, Configured: true}
+	}
+
+	plugins := make([]PluginInfo, 0, len(found))
+	for _, p := range found {
+		plugins = append(plugins, p)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// LookupPlugin returns the executable of the named plugin provider, from
+// configured or else from PATH
+func LookupPlugin(name string, configured map[string]string) (string, bool) {
+	if path, ok := configured[name]; ok {
+		return path, true
+	}
+	path, err := exec.LookPath(PluginPrefix + name)
+	return path, err == nil
+}
+
+// PluginProvider runs an external executable for each generation, speaking the
+// plugin protocol: a PluginRequest on stdin, a PluginResponse on stdout.
+// Anything the plugin writes to stderr is shown when it fails.
+type PluginProvider struct {
+	name string
+	path string
+	opts Options
+}
+
+func NewPluginProvider(name, path string, opts Options) *PluginProvider {
+	return &PluginProvider{name: name, path: path, opts: opts}
+}
+
+// Model returns the configured model, which the plugin may replace with its own default
+func (p *PluginProvider) Model() string {
+	return p.opts.Model
+}
+
+// GeneratePosts sends the request to the plugin and validates the posts it
+// returns. Plugins cannot be asked to repair or shorten posts, so invalid
+// output is an error and posts over their limit are truncated.
+func (p *PluginProvider) GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error) {
+	req.Platforms = NormalizePlatforms(req.Platforms)
+	prompt, err := buildPrompt(req)
+	if err != nil {
+		return nil, err
+	}
+
+	preq := PluginRequest{
+		Type:           PluginGenerate,
+		Platforms:      req.Platforms,
+		ProjectContext: req.ProjectContext,
+		Prompt:         prompt,
+		Options: PluginOptions{
+			Model:       p.opts.Model,
+			BaseURL:     p.opts.BaseURL,
+			APIKey:      p.opts.APIKey,
+			Headers:     p.opts.Headers,
+			Temperature: p.opts.Temperature,
+			TopP:        p.opts.TopP,
+			MaxTokens:   p.opts.MaxTokens,
+		},
+	}
+	for _, c := range req.Commits {
+		preq.Commits = append(preq.Commits, PluginCommit{Hash: c.Hash, Author: c.Author, Date: c.Date, Message: c.Message})
+	}
+	for _, t := range req.Targets() {
+		preq.Targets = append(preq.Targets, PluginTarget{
+			Key:      t.Key(),
+			Platform: t.Platform,
+			Lang:     t.Lang,
+			Limit:    CharLimit(t.Platform),
+			Thread:   req.threaded(t.Platform),
+		})
+	}
+
+	resp, err := p.call(ctx, preq)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := json.Marshal(map[string]any{"posts": resp.Posts})
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %v", p.name, err)
+	}
+	posts, err := validatePosts(string(out), req)
+	if err != nil {
+		return nil, &InvalidOutputError{Attempts: 1, Output: string(out), Err: err}
+	}
+
+	if resp.Usage != nil {
+		model := resp.Model
+		if model == "" {
+			model = p.opts.Model
+		}
+		meter := &UsageMeter{}
+		recordUsage(WithUsageMeter(ctx, meter), p.name, model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, prompt, string(out))
+		posts.Usage = meter.Usage()
+	}
+	if err := fitPosts(ctx, nil, posts, 0); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// Describe asks the plugin for a one-line description of itself
+func (p *PluginProvider) Describe(ctx context.Context) (string, error) {
+	resp, err := p.call(ctx, PluginRequest{Type: PluginDescribe})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(resp.Description), nil
+}
+
+// call runs the plugin with preq on stdin and reads its response. The plugin
+// is killed once ctx is done, or after defaultPluginTimeout if ctx has no deadline.
+func (p *PluginProvider) call(ctx context.Context, preq PluginRequest) (*PluginResponse, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultPluginTimeout)
+		defer cancel()
+	}
+	preq.Version = PluginProtocolVersion
+	preq.Provider = p.name
+	input, err := json.Marshal(preq)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %v", p.name, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Do not wait forever for output pipes a killed plugin's children keep open
+	cmd.WaitDelay = pluginWaitDelay
+	runErr := cmd.Run()
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("plugin %s did not finish: %w", p.name, err)
+	}
+	// A plugin that is not on PATH fails to be looked up, a missing or
+	// non-executable file fails to start
+	var execErr *exec.Error
+	var pathErr *fs.PathError
+	switch {
+	case errors.As(runErr, &execErr):
+		return nil, fmt.Errorf("cannot run plugin %s (%s): %v", p.name, p.path, execErr.Err)
+	case errors.As(runErr, &pathErr):
+		return nil, fmt.Errorf("cannot run plugin %s (%s): %v", p.name, p.path, pathErr.Err)
+	}
+
+	var resp PluginResponse
+	if err := json.Unmarshal(bytes.TrimSpace(stdout.Bytes()), &resp); err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("plugin %s failed: %v%s", p.name, runErr, stderrTail(stderr.String()))
+		}
+		return nil, fmt.Errorf("plugin %s returned invalid JSON: %v%s", p.name, err, stderrTail(stderr.String()))
+	}
+	if resp.Version != PluginProtocolVersion {
+		return nil, fmt.Errorf("plugin %s speaks protocol version %d, but CommitFeed speaks version %d", p.name, resp.Version, PluginProtocolVersion)
+	}
+	if resp.Error != nil {
+		return nil, &APIError{
+			Provider:   p.name,
+			Kind:       pluginErrorKinds[resp.Error.Kind],
+			Message:    resp.Error.Message,
+			RetryAfter: time.Duration(resp.Error.RetryAfter * float64(time.Second)),
+		}
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("plugin %s failed: %v%s", p.name, runErr, stderrTail(stderr.String()))
+	}
+	return &resp, nil
+}
+
+// stderrTail formats the last lines a plugin wrote to stderr for an error message
+func stderrTail(stderr string) string {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return ""
+	}
+	if len(lines) > pluginStderrLines {
+		lines = lines[len(lines)-pluginStderrLines:]
+	}
+	return "\nstderr:\n" + strings.Join(lines, "\n")
+}
@@ -0,0 +1,241 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPluginEnv makes the test binary act as a plugin instead of running the
+// tests, in the mode it is set to
+const testPluginEnv = "COMMITFEED_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(testPluginEnv); mode != "" {
+		os.Exit(runTestPlugin(mode))
+	}
+	os.Exit(m.Run())
+}
+
+// runTestPlugin answers one plugin request on stdin and returns the exit code
+func runTestPlugin(mode string) int {
+	var req PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, "bad request:", err)
+		return 2
+	}
+	reply := func(resp any) {
+		json.NewEncoder(os.Stdout).Encode(resp)
+	}
+
+	switch mode {
+	case "ok":
+		if req.Type == PluginDescribe {
+			reply(PluginResponse{Version: PluginProtocolVersion, Description: "Test plugin for " + req.Provider})
+			return 0
+		}
+		posts := map[string]any{}
+		for _, t := range req.Targets {
+			posts[t.Key] = fmt.Sprintf("Post for %s from %s, version %d.", t.Key, req.Provider, req.Version)
+			if t.Thread {
+				posts[t.Key] = []string{"First part.", "Second part."}
+			}
+		}
+		reply(PluginResponse{Version: PluginProtocolVersion, Posts: posts, Model: "test-1", Usage: &PluginUsage{PromptTokens: 10, CompletionTokens: 5}})
+	case "version":
+		reply(PluginResponse{Version: PluginProtocolVersion + 1, Posts: map[string]any{"linkedin": "From the future."}})
+	case "invalid":
+		fmt.Println("Sure! Here are your posts.")
+	case "crash":
+		for i := 1; i <= 8; i++ {
+			fmt.Fprintf(os.Stderr, "trace line %d\n", i)
+		}
+		return 3
+	case "error-exit":
+		reply(PluginResponse{Version: PluginProtocolVersion, Error: &PluginError{Kind: "quota", Message: "Out of credits", RetryAfter: 1.5}})
+		return 1
+	case "posts-exit":
+		reply(PluginResponse{Version: PluginProtocolVersion, Posts: map[string]any{"linkedin": "Half done."}})
+		fmt.Fprintln(os.Stderr, "lost the connection halfway")
+		return 1
+	case "hang":
+		time.Sleep(time.Minute)
+	case "hang-child":
+		// A child that keeps stdout open after the plugin is killed
+		child := exec.Command("sleep", "10")
+		child.Stdout = os.Stdout
+		child.Start()
+		time.Sleep(time.Minute)
+	}
+	return 0
+}
+
+// testPlugin installs the test binary as the plugin commitfeed-provider-test
+// running in mode, and returns its path
+func testPlugin(t *testing.T, mode string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the test plugin is a symlink to the test binary")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), PluginPrefix+"test")
+	if err := os.Symlink(exe, path); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(testPluginEnv, mode)
+	return path
+}
+
+func TestPluginGeneratesPosts(t *testing.T) {
+	p := NewPluginProvider("test", testPlugin(t, "ok"), Options{Model: "configured"})
+	req := replayRequest()
+	req.Platforms = []string{"linkedin", "bluesky"}
+	req.Thread = true
+
+	posts, err := p.GeneratePosts(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GeneratePosts: %v", err)
+	}
+	if got, want := posts.Get("linkedin"), "Post for linkedin from test, version 1."; got != want {
+		t.Errorf("linkedin post = %q, want %q", got, want)
+	}
+	if parts := posts.Posts["bluesky"].Parts; len(parts) != 2 || parts[0] != "1/2 First part." {
+		t.Errorf("bluesky thread = %q", parts)
+	}
+	if len(posts.Usage) != 1 || posts.Usage[0].Model != "test-1" || posts.Usage[0].PromptTokens != 10 {
+		t.Errorf("usage = %+v", posts.Usage)
+	}
+
+	desc, err := p.Describe(context.Background())
+	if err != nil || desc != "Test plugin for test" {
+		t.Errorf("Describe = %q, %v", desc, err)
+	}
+}
+
+func TestPluginFailures(t *testing.T) {
+	tests := []struct {
+		mode string
+		want []string // in the error message
+		kind ErrorKind
+	}{
+		{mode: "version", want: []string{"protocol version 2", "CommitFeed speaks version 1"}},
+		{mode: "invalid", want: []string{"returned invalid JSON"}},
+		{mode: "crash", want: []string{"failed: exit status 3", "stderr:\ntrace line 4\ntrace line 5\ntrace line 6\ntrace line 7\ntrace line 8"}},
+		{mode: "error-exit", want: []string{"Out of credits"}, kind: ErrQuota},
+		{mode: "posts-exit", want: []string{"failed: exit status 1", "lost the connection halfway"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			_, err := NewPluginProvider("test", testPlugin(t, tt.mode), Options{}).GeneratePosts(context.Background(), replayRequest())
+			if err == nil {
+				t.Fatal("got posts, want an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+			if tt.mode == "crash" && strings.Contains(err.Error(), "trace line 3") {
+				t.Errorf("error shows more than the last %d stderr lines: %q", pluginStderrLines, err)
+			}
+			var apiErr *APIError
+			if isAPIErr := errors.As(err, &apiErr); isAPIErr != (tt.kind != ErrUnknown) {
+				t.Fatalf("got %#v, want an APIError: %v", err, tt.kind != ErrUnknown)
+			}
+			if apiErr != nil && (apiErr.Kind != tt.kind || apiErr.RetryAfter != 1500*time.Millisecond) {
+				t.Errorf("got %v retrying after %v, want %v after 1.5s", apiErr.Kind, apiErr.RetryAfter, tt.kind)
+			}
+		})
+	}
+}
+
+func TestPluginTimeout(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("needs sleep")
+	}
+	for _, mode := range []string{"hang", "hang-child"} {
+		t.Run(mode, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			_, err := NewPluginProvider("test", testPlugin(t, mode), Options{}).GeneratePosts(ctx, replayRequest())
+			if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "did not finish") {
+				t.Errorf("got %v, want a deadline error", err)
+			}
+			// The killed plugin's child holds stdout open until WaitDelay gives up on it
+			if elapsed := time.Since(start); elapsed > pluginWaitDelay+2*time.Second {
+				t.Errorf("took %v to give up on the plugin", elapsed)
+			}
+		})
+	}
+}
+
+func TestPluginCannotRun(t *testing.T) {
+	dir := t.TempDir()
+	notExec := filepath.Join(dir, PluginPrefix+"test")
+	if err := os.WriteFile(notExec, []byte("#!/bin/sh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{filepath.Join(dir, "missing"), notExec, PluginPrefix + "not-on-path"} {
+		_, err := NewPluginProvider("test", path, Options{}).GeneratePosts(context.Background(), replayRequest())
+		if err == nil || !strings.HasPrefix(err.Error(), "cannot run plugin test ("+path+")") {
+			t.Errorf("%s: got %v, want a cannot-run error", path, err)
+		}
+	}
+}
+
+func TestFindPlugins(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	write := func(dir, name string, mode os.FileMode) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	alpha := write(first, PluginPrefix+"alpha", 0o755)
+	write(second, PluginPrefix+"alpha", 0o755)
+	beta := write(second, PluginPrefix+"beta", 0o755)
+	write(first, PluginPrefix+"notexec", 0o644)
+	write(first, "unrelated-tool", 0o755)
+	if err := os.Mkdir(filepath.Join(first, PluginPrefix+"dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", first+string(os.PathListSeparator)+second)
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins on Windows need an executable extension")
+	}
+
+	configured := map[string]string{"beta": "/opt/beta-plugin", "gamma": "/opt/gamma-plugin"}
+	got := FindPlugins(configured)
+	want := []PluginInfo{
+		{Name: "alpha", Path: alpha},
+		{Name: "beta", Path: "/opt/beta-plugin", Configured: true},
+		{Name: "gamma", Path: "/opt/gamma-plugin", Configured: true},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("FindPlugins = %+v, want %+v", got, want)
+	}
+
+	if path, ok := LookupPlugin("beta", nil); !ok || path != beta {
+		t.Errorf("LookupPlugin(beta) on PATH = %q, %v, want %q", path, ok, beta)
+	}
+	if path, ok := LookupPlugin("beta", configured); !ok || path != "/opt/beta-plugin" {
+		t.Errorf("LookupPlugin(beta) configured = %q, %v", path, ok)
+	}
+	if _, ok := LookupPlugin("notexec", nil); ok {
+		t.Error("found a plugin that is not executable")
+	}
+}
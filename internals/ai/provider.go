@@ -1,12 +1,115 @@
 package ai
 
-import "github.com/kurtiz/commit-feed/internals/git"
+import (
+	"context"
 
+	"github.com/kurtiz/commit-feed/internals/git"
+)
+
+// Post is a single generated post for one platform, in one language
+type Post struct {
+	Platform string
+	// Lang is the language code the post was requested in, or "" if none was
+	Lang string
+	Text string
+	// Variants holds the alternative texts when more than one was requested.
+	// Text is the first of them until one is picked.
+	Variants []string
+	// Parts holds the numbered parts of a thread, in order. Text joins them.
+	Parts []string
+	// Provider is the provider that wrote the post when platforms were
+	// generated separately; "" means the one of the GeneratedPosts
+	Provider string
+}
+
+// Request describes a generation: the commits to write about and the posts wanted
+type Request struct {
+	Commits        []git.Commit
+	Platforms      []string
+	ProjectContext string
+	// Variants is the number of alternative posts to generate per platform; 0 means 1.
+	// It is ignored for threads.
+	Variants int
+	// Thread asks for a numbered thread instead of a single post on the
+	// platforms that support threads (see SupportsThreads)
+	Thread bool
+	// ShortenAttempts is how often a post over its platform's length limit is
+	// sent back for shortening before it is truncated. 0 means
+	// DefaultShortenAttempts; a negative value truncates right away.
+	ShortenAttempts int
+	// Templates renders the prompt; nil uses DefaultTemplates
+	Templates *Templates
+	// Voice, if set, is the voice the posts should be written in
+	Voice *Voice
+	// Languages maps a platform to the languages its post is written in, one
+	// post per language. Platforms without an entry get a single post.
+	Languages map[string][]string
+}
+
+// GeneratedPosts holds the generated posts keyed by target (see Target.Key)
 type GeneratedPosts struct {
-	LinkedIn string
-	Twitter  string
+	Posts map[string]Post
+	// Provider is the name of the provider that produced the posts, when known
+	Provider string
+	// Usage is the token usage of the generation, per model, including
+	// summaries, repairs and shortening
+	Usage []Usage
+}
+
+// Get returns the post text for a platform or target key such as "linkedin:fr",
+// or an empty string if none was generated
+func (g *GeneratedPosts) Get(key string) string {
+	if g == nil {
+		return ""
+	}
+	return g.Posts[ParseTarget(key).Key()].Text
+}
+
+// PostProvider returns the name of the provider that wrote the post for a
+// platform or target key
+func (g *GeneratedPosts) PostProvider(key string) string {
+	if p := g.Posts[ParseTarget(key).Key()].Provider; p != "" {
+		return p
+	}
+	return g.Provider
+}
+
+// Targets lists the posts the request asks for: one per platform, or one per
+// language of a platform with languages, in platform order
+func (r Request) Targets() []Target {
+	langs := make(map[string][]string, len(r.Languages))
+	for p, l := range r.Languages {
+		p = NormalizePlatform(p)
+		langs[p] = NormalizeLanguages(append(langs[p], l...))
+	}
+	var targets []Target
+	for _, p := range NormalizePlatforms(r.Platforms) {
+		if len(langs[p]) == 0 {
+			targets = append(targets, Target{Platform: p})
+			continue
+		}
+		for _, l := range langs[p] {
+			targets = append(targets, Target{Platform: p, Lang: l})
+		}
+	}
+	return targets
+}
+
+// threaded reports whether the request wants a thread for platform
+func (r Request) threaded(platform string) bool {
+	return r.Thread && SupportsThreads(platform)
+}
+
+// ModelName returns the model a provider generates with, or "" if it does not report one
+func ModelName(p Provider) string {
+	if m, ok := p.(interface{ Model() string }); ok {
+		return m.Model()
+	}
+	return ""
 }
 
+// Provider generates posts for the requested platforms from a list of commits.
+// Implementations must stop and return ctx.Err() once ctx is cancelled.
 type Provider interface {
-	GeneratePosts(commits []git.Commit, platforms []string, projectContext string) (*GeneratedPosts, error)
+	GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error)
 }
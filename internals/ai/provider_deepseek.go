@@ -1,61 +1,63 @@
 package ai
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"net/http"
+	"context"
 	"os"
-
-	"github.com/kurtiz/commit-feed/internals/git"
 )
 
 type DeepSeekProvider struct {
-	apiKey string
+	endpoint chatEndpoint
+	model    string
+	opts     Options
 }
 
-func NewDeepSeekProvider(apiKey string) *DeepSeekProvider {
+func NewDeepSeekProvider(opts Options) *DeepSeekProvider {
+	apiKey := opts.APIKey
 	if apiKey == "" {
 		apiKey = os.Getenv("DEEPSEEK_API_KEY")
 	}
-	return &DeepSeekProvider{apiKey: apiKey}
+	return &DeepSeekProvider{
+		endpoint: chatEndpoint{name: "deepseek", url: "https://api.deepseek.com/v1/chat/completions", apiKey: apiKey, streamUsage: true, client: opts.HTTPClient},
+		model:    opts.modelOr("deepseek-chat"),
+		opts:     opts,
+	}
 }
 
-func (d *DeepSeekProvider) GeneratePosts(commits []git.Commit, platforms []string, projectContext string) (*GeneratedPosts, error) {
-	body := map[string]interface{}{
-		"model": "deepseek-chat",
-		"messages": []map[string]string{
-			{"role": "system", "content": "You are CommitFeed, summarizing commits into social posts."},
-			{"role": "user", "content": buildPrompt(commits, platforms, projectContext)},
-		},
-	}
+// Model returns the name of the model used for generation
+func (d *DeepSeekProvider) Model() string {
+	return d.model
+}
 
-	data, _ := json.Marshal(body)
+func (d *DeepSeekProvider) GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error) {
+	return generateStructured(ctx, d, req)
+}
 
-	req, _ := http.NewRequest("POST", "https://api.deepseek.com/v1/chat/completions", bytes.NewBuffer(data))
-	req.Header.Set("Authorization", "Bearer "+d.apiKey)
-	req.Header.Set("Content-Type", "application/json")
+// StreamPosts generates posts like GeneratePosts, reporting post text as it streams in
+func (d *DeepSeekProvider) StreamPosts(ctx context.Context, req Request, onDelta func(key, text string)) (*GeneratedPosts, error) {
+	return streamStructured(ctx, d, req, onDelta)
+}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("deepseek request error: %v", err)
-	}
-	defer resp.Body.Close()
-
-	var parsed struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
-		return nil, fmt.Errorf("failed to parse deepseek response: %v", err)
-	}
+// complete runs a chat completion, using DeepSeek's JSON output mode when a schema is requested
+func (d *DeepSeekProvider) complete(ctx context.Context, req chatRequest) (string, error) {
+	return d.endpoint.complete(ctx, d.payload(req))
+}
 
-	if len(parsed.Choices) == 0 {
-		return nil, fmt.Errorf("no response from deepseek")
+// stream runs a streaming chat completion
+func (d *DeepSeekProvider) stream(ctx context.Context, req chatRequest, onChunk func(string)) (string, error) {
+	return d.endpoint.stream(ctx, d.payload(req), onChunk)
+}
+
+// payload builds the chat completion request body
+func (d *DeepSeekProvider) payload(req chatRequest) map[string]interface{} {
+	body := map[string]interface{}{
+		"model":    d.model,
+		"messages": chatMessagesPayload(req.Messages),
+	}
+	applyChatParams(body, d.opts)
+	if req.Schema != nil {
+		// DeepSeek only supports free-form JSON objects; the schema itself is enforced by validation
+		body["response_format"] = map[string]string{"type": "json_object"}
 	}
 
-	return parseResponse(parsed.Choices[0].Message.Content), nil
+	return body
 }
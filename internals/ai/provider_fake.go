@@ -0,0 +1,147 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// FakeProviderName is the name the fake provider is configured and reported by
+const FakeProviderName = "fake"
+
+// FakeProvider answers with scripted responses instead of calling a model, so
+// generation can be tested end to end without a network. Its answers go
+// through the same validation, repair and length fitting as a real model's.
+type FakeProvider struct {
+	// Responses are the raw model outputs returned in order, one per chat
+	// completion. Once they run out, an answer matching the request's schema
+	// is made up, with posts such as "Fake LinkedIn post."
+	Responses []string
+	// Err, when set, is returned from every completion instead
+	Err error
+
+	model   string
+	mu      sync.Mutex
+	next    int
+	prompts []string
+}
+
+func NewFakeProvider(opts Options, responses ...string) *FakeProvider {
+	return &FakeProvider{Responses: responses, model: opts.modelOr(FakeProviderName)}
+}
+
+// Model returns the name of the model used for generation
+func (f *FakeProvider) Model() string {
+	return f.model
+}
+
+func (f *FakeProvider) GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error) {
+	return generateStructured(ctx, f, req)
+}
+
+// StreamPosts generates posts like GeneratePosts, reporting post text as it streams in
+func (f *FakeProvider) StreamPosts(ctx context.Context, req Request, onDelta func(key, text string)) (*GeneratedPosts, error) {
+	return streamStructured(ctx, f, req, onDelta)
+}
+
+// Prompts returns the last user message of every completion so far, in order
+func (f *FakeProvider) Prompts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.prompts...)
+}
+
+// complete returns the next scripted response, without any reasoning trace
+func (f *FakeProvider) complete(ctx context.Context, req chatRequest) (string, error) {
+	out, err := f.respond(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return cleanOutput(ctx, FakeProviderName, f.model, out, ""), nil
+}
+
+// stream returns the next scripted response in small chunks
+func (f *FakeProvider) stream(ctx context.Context, req chatRequest, onChunk func(string)) (string, error) {
+	out, err := f.respond(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	filter := newReasoningFilter(f.model, onChunk)
+	for rest := []rune(out); len(rest) > 0; {
+		n := min(len(rest), 16)
+		filter.Write(string(rest[:n]))
+		rest = rest[n:]
+	}
+	return cleanOutput(ctx, FakeProviderName, f.model, out, ""), nil
+}
+
+// respond returns the raw output of the next completion
+func (f *FakeProvider) respond(ctx context.Context, req chatRequest) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if f.Err != nil {
+		return "", f.Err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var prompt string
+	for _, m := range req.Messages {
+		if m.Role == "user" {
+			prompt = m.Content
+		}
+	}
+	f.prompts = append(f.prompts, prompt)
+
+	if f.next < len(f.Responses) {
+		f.next++
+		return f.Responses[f.next-1], nil
+	}
+	if req.Schema == nil {
+		return "Fake summary of the changes.", nil
+	}
+	out, err := json.Marshal(fakeValue(req.Schema, ""))
+	if err != nil {
+		return "", fmt.Errorf("fake provider: %v", err)
+	}
+	return string(out), nil
+}
+
+// fakeValue makes up a value matching schema. Strings under a post's key
+// become a fake post for it; lists of objects, such as unsupported claims,
+// are left empty.
+func fakeValue(schema *jsonSchema, key string) any {
+	switch schema.Type {
+	case "object":
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		obj := make(map[string]any, len(names))
+		for _, name := range names {
+			obj[name] = fakeValue(schema.Properties[name], name)
+		}
+		return obj
+	case "array":
+		if schema.Items == nil || schema.Items.Type != "string" {
+			return []any{}
+		}
+		return []any{fakePost(key) + ", part one.", fakePost(key) + ", part two."}
+	case "string":
+		return fakePost(key) + "."
+	default:
+		return nil
+	}
+}
+
+// fakePost names the fake post for a target key, e.g. "Fake LinkedIn (French) post"
+func fakePost(key string) string {
+	if key == "" {
+		return "Fake text"
+	}
+	return "Fake " + ParseTarget(key).Label() + " post"
+}
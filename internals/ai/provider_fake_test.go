@@ -0,0 +1,83 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFakeProviderRepairsInvalidOutput(t *testing.T) {
+	valid := `{"posts": {"linkedin": "Widgetly exports to CSV.", "twitter": "CSV export is here."}}`
+	fake := NewFakeProvider(Options{}, "Sure! Here are your posts.", valid)
+
+	posts, err := fake.GeneratePosts(context.Background(), replayRequest())
+	if err != nil {
+		t.Fatalf("GeneratePosts: %v", err)
+	}
+	if got := posts.Get("linkedin"); got != "Widgetly exports to CSV." {
+		t.Errorf("linkedin post = %q", got)
+	}
+	if got := posts.Get("twitter"); got != "CSV export is here." {
+		t.Errorf("twitter post = %q", got)
+	}
+
+	prompts := fake.Prompts()
+	if len(prompts) != 2 {
+		t.Fatalf("got %d completions, want the request and one repair", len(prompts))
+	}
+	if !strings.Contains(prompts[1], "Your previous response was invalid") {
+		t.Errorf("second prompt is not a repair request:\n%s", prompts[1])
+	}
+}
+
+func TestFakeProviderGivesUpAfterRepairs(t *testing.T) {
+	fake := NewFakeProvider(Options{}, "not json", "still not json", `{"posts": {"linkedin": "only one"}}`)
+
+	_, err := fake.GeneratePosts(context.Background(), replayRequest())
+	var invalid *InvalidOutputError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("got %v, want an InvalidOutputError", err)
+	}
+	if invalid.Attempts != maxRepairAttempts+1 {
+		t.Errorf("attempts = %d, want %d", invalid.Attempts, maxRepairAttempts+1)
+	}
+	if invalid.Output != `{"posts": {"linkedin": "only one"}}` {
+		t.Errorf("output = %q, want the last response", invalid.Output)
+	}
+}
+
+func TestFakeProviderMakesUpPosts(t *testing.T) {
+	p, err := NewProvider(FakeProviderName, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := replayRequest()
+	req.Platforms = []string{"linkedin", "bluesky"}
+	req.Thread = true
+
+	posts, err := p.GeneratePosts(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GeneratePosts: %v", err)
+	}
+	if got := posts.Get("linkedin"); got != "Fake LinkedIn post." {
+		t.Errorf("linkedin post = %q", got)
+	}
+	if parts := posts.Posts["bluesky"].Parts; len(parts) != 2 {
+		t.Errorf("bluesky thread has %d parts, want 2: %q", len(parts), parts)
+	}
+}
+
+func TestFakeProviderScriptedThroughOptions(t *testing.T) {
+	p, err := NewProvider(FakeProviderName, Options{FakeResponses: []string{`{"posts": {"linkedin": "Scripted.", "twitter": "Scripted too."}}`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	posts, err := p.GeneratePosts(context.Background(), replayRequest())
+	if err != nil {
+		t.Fatalf("GeneratePosts: %v", err)
+	}
+	if got := posts.Get("twitter"); got != "Scripted too." {
+		t.Errorf("twitter post = %q", got)
+	}
+}
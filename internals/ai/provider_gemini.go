@@ -2,36 +2,278 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
 
 	genai "github.com/google/generative-ai-go/genai"
-	"github.com/kurtiz/commit-feed/internals/git"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
 type GeminiProvider struct {
 	client *genai.Client
+	model  string
+	opts   Options
 }
 
-func NewGeminiProvider(apiKey string) *GeminiProvider {
+// NewGeminiProvider creates a Gemini provider, failing if the client cannot be set up
+func NewGeminiProvider(opts Options) (*GeminiProvider, error) {
+	apiKey := opts.APIKey
 	if apiKey == "" {
 		apiKey = os.Getenv("GEMINI_API_KEY")
 	}
 	ctx := context.Background()
-	client, _ := genai.NewClient(ctx, option.WithAPIKey(apiKey))
-	return &GeminiProvider{client: client}
+	clientOpts := []option.ClientOption{option.WithAPIKey(apiKey)}
+	if opts.HTTPClient != nil {
+		// A custom client is used as is, so it has to send the API key itself
+		clientOpts = append(clientOpts, option.WithHTTPClient(&http.Client{
+			Timeout:   opts.HTTPClient.Timeout,
+			Transport: &apiKeyTransport{key: apiKey, base: opts.HTTPClient.Transport},
+		}))
+	}
+	client, err := genai.NewClient(ctx, clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gemini client: %v", err)
+	}
+	return &GeminiProvider{client: client, model: opts.modelOr("gemini-1.5-flash"), opts: opts}, nil
+}
+
+// Model returns the name of the model used for generation
+func (g *GeminiProvider) Model() string {
+	return g.model
+}
+
+func (g *GeminiProvider) GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error) {
+	return generateStructured(ctx, g, req)
+}
+
+// StreamPosts generates posts like GeneratePosts, reporting post text as it streams in
+func (g *GeminiProvider) StreamPosts(ctx context.Context, req Request, onDelta func(key, text string)) (*GeneratedPosts, error) {
+	return streamStructured(ctx, g, req, onDelta)
 }
 
-func (g *GeminiProvider) GeneratePosts(commits []git.Commit, platforms []string, projectContext string) (*GeneratedPosts, error) {
-	prompt := buildPrompt(commits, platforms, projectContext)
+// complete runs a chat completion, using Gemini's JSON response mode when a schema is requested
+func (g *GeminiProvider) complete(ctx context.Context, req chatRequest) (string, error) {
+	_, cs, last, err := g.session(req)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := cs.SendMessage(ctx, genai.Text(last))
+	if err != nil {
+		return "", geminiError(err)
+	}
+	g.recordUsage(ctx, resp.UsageMetadata, req, geminiText(resp))
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return "", fmt.Errorf("no response from gemini")
+	}
+
+	return cleanOutput(ctx, "gemini", g.model, geminiText(resp), ""), nil
+}
 
-	resp, err := g.client.GenerativeModel("gemini-1.5-flash").
-		GenerateContent(context.Background(), genai.Text(prompt))
+// completeN asks Gemini for req.N candidates in a single request
+func (g *GeminiProvider) completeN(ctx context.Context, req chatRequest) ([]string, error) {
+	model, cs, last, err := g.session(req)
 	if err != nil {
-		return nil, fmt.Errorf("gemini error: %v", err)
+		return nil, err
 	}
+	// Chat sessions always ask for one candidate, so only single-turn
+	// conversations can be sent with a candidate count
+	if len(cs.History) > 0 {
+		out, err := g.complete(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return []string{out}, nil
+	}
+
+	model.SetCandidateCount(int32(req.N))
+	resp, err := model.GenerateContent(ctx, genai.Text(last))
+	if err != nil {
+		return nil, geminiError(err)
+	}
+	var outputs []string
+	for _, c := range resp.Candidates {
+		if text := geminiCandidateText(c); text != "" {
+			outputs = append(outputs, text)
+		}
+	}
+	g.recordUsage(ctx, resp.UsageMetadata, req, outputs...)
+	for i, out := range outputs {
+		outputs[i] = cleanOutput(ctx, "gemini", g.model, out, "")
+	}
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("no response from gemini")
+	}
+	return outputs, nil
+}
+
+// stream runs a chat completion through Gemini's streaming API
+func (g *GeminiProvider) stream(ctx context.Context, req chatRequest, onChunk func(string)) (string, error) {
+	_, cs, last, err := g.session(req)
+	if err != nil {
+		return "", err
+	}
+
+	filter := newReasoningFilter(g.model, onChunk)
+	var content strings.Builder
+	var usage *genai.UsageMetadata
+	iter := cs.SendMessageStream(ctx, genai.Text(last))
+	for {
+		resp, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return "", geminiError(err)
+		}
+		// Each chunk reports the usage so far; the last one has the totals
+		if resp.UsageMetadata != nil {
+			usage = resp.UsageMetadata
+		}
+		if chunk := geminiText(resp); chunk != "" {
+			content.WriteString(chunk)
+			filter.Write(chunk)
+		}
+	}
+	g.recordUsage(ctx, usage, req, content.String())
+	if content.Len() == 0 {
+		return "", fmt.Errorf("no response from gemini")
+	}
+
+	return cleanOutput(ctx, "gemini", g.model, content.String(), ""), nil
+}
 
-	output := resp.Candidates[0].Content.Parts[0].(genai.Text)
-	return parseResponse(string(output)), nil
+// recordUsage counts the tokens Gemini reports for a call
+func (g *GeminiProvider) recordUsage(ctx context.Context, usage *genai.UsageMetadata, req chatRequest, outputs ...string) {
+	var prompt, completion int
+	if usage != nil {
+		prompt, completion = int(usage.PromptTokenCount), int(usage.CandidatesTokenCount)
+	}
+	recordUsage(ctx, "gemini", g.model, prompt, completion, messagesText(req.Messages), outputs...)
+}
+
+// session prepares a chat session holding all but the last message, which is
+// returned separately, along with the model the session runs on
+func (g *GeminiProvider) session(req chatRequest) (*genai.GenerativeModel, *genai.ChatSession, string, error) {
+	if g.client == nil {
+		return nil, nil, "", fmt.Errorf("gemini client is not initialized")
+	}
+
+	model := g.client.GenerativeModel(g.model)
+	if g.opts.Temperature != nil {
+		model.SetTemperature(*g.opts.Temperature)
+	}
+	if g.opts.TopP != nil {
+		model.SetTopP(*g.opts.TopP)
+	}
+	if g.opts.MaxTokens > 0 {
+		model.SetMaxOutputTokens(int32(g.opts.MaxTokens))
+	}
+	if req.Schema != nil {
+		model.ResponseMIMEType = "application/json"
+		model.ResponseSchema = toGeminiSchema(req.Schema)
+	}
+
+	// Gemini takes the system prompt separately and calls the assistant role "model"
+	cs := model.StartChat()
+	var last string
+	for i, m := range req.Messages {
+		switch {
+		case m.Role == "system":
+			model.SystemInstruction = genai.NewUserContent(genai.Text(m.Content))
+		case i == len(req.Messages)-1:
+			last = m.Content
+		case m.Role == "assistant":
+			cs.History = append(cs.History, &genai.Content{Role: "model", Parts: []genai.Part{genai.Text(m.Content)}})
+		default:
+			cs.History = append(cs.History, genai.NewUserContent(genai.Text(m.Content)))
+		}
+	}
+	return model, cs, last, nil
+}
+
+// geminiText joins the text parts of the first candidate
+func geminiText(resp *genai.GenerateContentResponse) string {
+	if resp == nil || len(resp.Candidates) == 0 {
+		return ""
+	}
+	return geminiCandidateText(resp.Candidates[0])
+}
+
+// geminiCandidateText joins the text parts of a candidate
+func geminiCandidateText(c *genai.Candidate) string {
+	if c == nil || c.Content == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, part := range c.Content.Parts {
+		if text, ok := part.(genai.Text); ok {
+			sb.WriteString(string(text))
+		}
+	}
+	return sb.String()
+}
+
+// geminiError converts Gemini client errors into typed API errors
+func geminiError(err error) error {
+	var blocked *genai.BlockedError
+	if errors.As(err, &blocked) {
+		return &APIError{Provider: "gemini", Kind: ErrContentFiltered, Message: blocked.Error()}
+	}
+	var coded interface{ HTTPCode() int }
+	if errors.As(err, &coded) && coded.HTTPCode() > 0 {
+		typed := newAPIError("gemini", coded.HTTPCode(), nil, []byte(err.Error()))
+		typed.Message = err.Error()
+		return typed
+	}
+	return fmt.Errorf("gemini error: %w", err)
+}
+
+// toGeminiSchema converts a JSON schema into Gemini's schema type
+func toGeminiSchema(s *jsonSchema) *genai.Schema {
+	if s == nil {
+		return nil
+	}
+	out := &genai.Schema{Required: s.Required, Items: toGeminiSchema(s.Items)}
+	switch s.Type {
+	case "object":
+		out.Type = genai.TypeObject
+	case "array":
+		out.Type = genai.TypeArray
+	case "integer":
+		out.Type = genai.TypeInteger
+	case "number":
+		out.Type = genai.TypeNumber
+	case "boolean":
+		out.Type = genai.TypeBoolean
+	default:
+		out.Type = genai.TypeString
+	}
+	if len(s.Properties) > 0 {
+		out.Properties = make(map[string]*genai.Schema, len(s.Properties))
+		for name, prop := range s.Properties {
+			out.Properties[name] = toGeminiSchema(prop)
+		}
+	}
+	return out
+}
+
+// apiKeyTransport adds the Gemini API key to requests sent through a custom HTTP client
+type apiKeyTransport struct {
+	key  string
+	base http.RoundTripper
+}
+
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("x-goog-api-key", t.key)
+	return base.RoundTrip(req)
 }
@@ -0,0 +1,87 @@
+package ai
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// grokBaseURL is the xAI API endpoint used when no other base URL is given
+const grokBaseURL = "https://api.x.ai/v1"
+
+// GrokProvider talks to xAI's OpenAI-compatible chat completions API
+type GrokProvider struct {
+	endpoint chatEndpoint
+	model    string
+	opts     Options
+}
+
+// NewGrokProvider creates a Grok provider. opts.BaseURL replaces the public
+// xAI API, e.g. to point the provider at a local HTTP stand-in.
+func NewGrokProvider(opts Options) *GrokProvider {
+	apiKey := opts.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("XAI_API_KEY")
+	}
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = grokBaseURL
+	}
+	return &GrokProvider{
+		endpoint: chatEndpoint{
+			name:        "grok",
+			url:         strings.TrimRight(baseURL, "/") + "/chat/completions",
+			apiKey:      apiKey,
+			streamUsage: true,
+			client:      opts.HTTPClient,
+		},
+		model: opts.modelOr("grok-3-mini"),
+		opts:  opts,
+	}
+}
+
+// Model returns the name of the model used for generation
+func (g *GrokProvider) Model() string {
+	return g.model
+}
+
+func (g *GrokProvider) GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error) {
+	return generateStructured(ctx, g, req)
+}
+
+// StreamPosts generates posts like GeneratePosts, reporting post text as it streams in
+func (g *GrokProvider) StreamPosts(ctx context.Context, req Request, onDelta func(key, text string)) (*GeneratedPosts, error) {
+	return streamStructured(ctx, g, req, onDelta)
+}
+
+// complete runs a chat completion, using xAI structured outputs when a schema is requested
+func (g *GrokProvider) complete(ctx context.Context, req chatRequest) (string, error) {
+	return g.endpoint.complete(ctx, g.payload(req))
+}
+
+// completeN runs a chat completion that returns req.N choices
+func (g *GrokProvider) completeN(ctx context.Context, req chatRequest) ([]string, error) {
+	return g.endpoint.completeChoices(ctx, g.payload(req))
+}
+
+// stream runs a streaming chat completion
+func (g *GrokProvider) stream(ctx context.Context, req chatRequest, onChunk func(string)) (string, error) {
+	return g.endpoint.stream(ctx, g.payload(req), onChunk)
+}
+
+// payload builds the chat completion request body
+func (g *GrokProvider) payload(req chatRequest) map[string]interface{} {
+	body := map[string]interface{}{
+		"model":    g.model,
+		"messages": chatMessagesPayload(req.Messages),
+	}
+	applyChatParams(body, g.opts)
+	if req.N > 1 {
+		body["n"] = req.N
+	}
+	if req.Schema != nil {
+		body["response_format"] = jsonSchemaResponseFormat(req.Schema)
+	}
+
+	return body
+}
@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// grokServer stands in for the xAI API, answering every chat completion with
+// status and body and keeping the last request it received
+func grokServer(t *testing.T, status int, header http.Header, body string) (*httptest.Server, *http.Request, *map[string]interface{}) {
+	t.Helper()
+	var got http.Request
+	payload := map[string]interface{}{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = *r
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("request body: %v", err)
+		}
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got, &payload
+}
+
+// grokOptions points a Grok provider at srv. The test server's client skips
+// the retry transport, so rate-limited requests fail right away.
+func grokOptions(srv *httptest.Server) Options {
+	return Options{APIKey: "test-key", BaseURL: srv.URL + "/v1", HTTPClient: srv.Client()}
+}
+
+func TestGrokGeneratesPosts(t *testing.T) {
+	content, _ := json.Marshal(`{"posts": {"linkedin": "Widgetly now exports to CSV.", "twitter": "CSV export is here."}}`)
+	srv, got, payload := grokServer(t, http.StatusOK, nil, `{
+		"id": "chatcmpl-1",
+		"model": "grok-3-mini",
+		"choices": [{"index": 0, "message": {"role": "assistant", "content": `+string(content)+`}, "finish_reason": "stop"}],
+		"usage": {"prompt_tokens": 420, "completion_tokens": 36, "total_tokens": 456}
+	}`)
+
+	posts, err := NewGrokProvider(grokOptions(srv)).GeneratePosts(context.Background(), replayRequest())
+	if err != nil {
+		t.Fatalf("GeneratePosts: %v", err)
+	}
+	if text := posts.Get("twitter"); text != "CSV export is here." {
+		t.Errorf("twitter post = %q", text)
+	}
+	if got.URL.Path != "/v1/chat/completions" {
+		t.Errorf("path = %q, want /v1/chat/completions", got.URL.Path)
+	}
+	if auth := got.Header.Get("Authorization"); auth != "Bearer test-key" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if model := (*payload)["model"]; model != "grok-3-mini" {
+		t.Errorf("model = %v, want grok-3-mini", model)
+	}
+	if _, ok := (*payload)["response_format"]; !ok {
+		t.Error("request does not ask for structured output")
+	}
+	if len(posts.Usage) != 1 || posts.Usage[0].PromptTokens != 420 || posts.Usage[0].CompletionTokens != 36 {
+		t.Errorf("usage = %+v", posts.Usage)
+	}
+}
+
+func TestGrokErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		header     http.Header
+		body       string
+		kind       ErrorKind
+		message    string
+		retryAfter time.Duration
+	}{
+		{
+			name:    "unauthorized",
+			status:  http.StatusUnauthorized,
+			body:    `{"code": "Client specified an invalid argument", "error": "Incorrect API key provided: te***ey."}`,
+			kind:    ErrAuth,
+			message: "Incorrect API key provided: te***ey.",
+		},
+		{
+			name:       "rate limited",
+			status:     http.StatusTooManyRequests,
+			header:     http.Header{"Retry-After": {"7"}},
+			body:       `{"error": {"message": "Too many requests, slow down."}}`,
+			kind:       ErrRateLimit,
+			message:    "Too many requests, slow down.",
+			retryAfter: 7 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _, _ := grokServer(t, tt.status, tt.header, tt.body)
+
+			_, err := NewGrokProvider(grokOptions(srv)).GeneratePosts(context.Background(), replayRequest())
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got %v, want an APIError", err)
+			}
+			if apiErr.Provider != "grok" || apiErr.Kind != tt.kind || apiErr.StatusCode != tt.status {
+				t.Errorf("got %s %v (%d), want grok %v (%d)", apiErr.Provider, apiErr.Kind, apiErr.StatusCode, tt.kind, tt.status)
+			}
+			if apiErr.Message != tt.message {
+				t.Errorf("message = %q, want %q", apiErr.Message, tt.message)
+			}
+			if apiErr.RetryAfter != tt.retryAfter {
+				t.Errorf("RetryAfter = %v, want %v", apiErr.RetryAfter, tt.retryAfter)
+			}
+		})
+	}
+}
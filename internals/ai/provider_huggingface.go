@@ -1,77 +1,58 @@
 package ai
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-
-	"github.com/kurtiz/commit-feed/internals/git"
+	"context"
 )
 
 // HuggingFaceProvider represents the Hugging Face API client
 type HuggingFaceProvider struct {
-	apiKey string
-	model  string
+	endpoint chatEndpoint
+	model    string
+	opts     Options
 }
 
-func NewHuggingFaceProvider(apiKey string) *HuggingFaceProvider {
+func NewHuggingFaceProvider(opts Options) *HuggingFaceProvider {
 	return &HuggingFaceProvider{
-		apiKey: apiKey,
-		model:  "openai/gpt-oss-20b:groq", // chat-capable model
+		endpoint: chatEndpoint{name: "huggingface", url: "https://router.huggingface.co/v1/chat/completions", apiKey: opts.APIKey, client: opts.HTTPClient},
+		model:    opts.modelOr("openai/gpt-oss-20b:groq"), // chat-capable model
+		opts:     opts,
 	}
 }
 
-// GeneratePosts builds a prompt and requests AI-generated posts
-func (h *HuggingFaceProvider) GeneratePosts(commits []git.Commit, platforms []string, projectContext string) (*GeneratedPosts, error) {
-	prompt := buildPrompt(commits, platforms, projectContext)
-
-	payload := map[string]interface{}{
-		"model": h.model,
-		"messages": []map[string]string{
-			{"role": "user", "content": prompt},
-		},
-		"stream": false,
-	}
-
-	body, _ := json.Marshal(payload)
+// Model returns the name of the model used for generation
+func (h *HuggingFaceProvider) Model() string {
+	return h.model
+}
 
-	req, err := http.NewRequest("POST", "https://router.huggingface.co/v1/chat/completions", bytes.NewBuffer(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
+// GeneratePosts builds a prompt and requests AI-generated posts
+func (h *HuggingFaceProvider) GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error) {
+	return generateStructured(ctx, h, req)
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	if h.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+h.apiKey)
-	}
+// StreamPosts generates posts like GeneratePosts, reporting post text as it streams in
+func (h *HuggingFaceProvider) StreamPosts(ctx context.Context, req Request, onDelta func(key, text string)) (*GeneratedPosts, error) {
+	return streamStructured(ctx, h, req, onDelta)
+}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("huggingface request failed: %v", err)
-	}
-	defer resp.Body.Close()
+// complete runs a chat completion through the Hugging Face router.
+// The router has no portable JSON mode, so the schema is enforced by validation only.
+func (h *HuggingFaceProvider) complete(ctx context.Context, req chatRequest) (string, error) {
+	return h.endpoint.complete(ctx, h.payload(req))
+}
 
-	data, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("huggingface error: %s", string(data))
-	}
+// stream runs a streaming chat completion
+func (h *HuggingFaceProvider) stream(ctx context.Context, req chatRequest, onChunk func(string)) (string, error) {
+	return h.endpoint.stream(ctx, h.payload(req), onChunk)
+}
 
-	var parsed struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
-	}
-	if err := json.Unmarshal(data, &parsed); err != nil {
-		return nil, fmt.Errorf("failed to parse huggingface response: %v", err)
-	}
-	if len(parsed.Choices) == 0 || parsed.Choices[0].Message.Content == "" {
-		return nil, fmt.Errorf("no response content returned from model")
+// payload builds the chat completion request body
+func (h *HuggingFaceProvider) payload(req chatRequest) map[string]interface{} {
+	payload := map[string]interface{}{
+		"model":    h.model,
+		"messages": chatMessagesPayload(req.Messages),
+		"stream":   false,
 	}
+	applyChatParams(payload, h.opts)
 
-	text := parsed.Choices[0].Message.Content
-	return parseResponse(text), nil
+	return payload
 }
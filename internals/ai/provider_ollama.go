@@ -0,0 +1,192 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultOllamaHost is where a local Ollama daemon listens by default
+	DefaultOllamaHost = "http://localhost:11434"
+	// DefaultOllamaModel is used when no model is configured
+	DefaultOllamaModel = "llama3.2"
+	// ollamaContextWindow is the context size Ollama runs models with by default (num_ctx)
+	ollamaContextWindow = 4096
+)
+
+// ErrOllamaNotRunning is returned when the Ollama daemon cannot be reached
+var ErrOllamaNotRunning = errors.New("ollama is not running")
+
+// OllamaProvider generates posts with a model served by a local Ollama daemon,
+// so commit messages never leave the machine
+type OllamaProvider struct {
+	host  string
+	model string
+	opts  Options
+}
+
+// NewOllamaProvider creates an Ollama provider. An empty opts.BaseURL falls back to
+// OLLAMA_HOST and then DefaultOllamaHost; an empty model to DefaultOllamaModel.
+func NewOllamaProvider(opts Options) *OllamaProvider {
+	return &OllamaProvider{
+		host:  OllamaHost(opts.BaseURL),
+		model: opts.modelOr(DefaultOllamaModel),
+		opts:  opts,
+	}
+}
+
+// OllamaHost resolves the Ollama base URL, adding a scheme if it is missing
+func OllamaHost(host string) string {
+	if host == "" {
+		host = os.Getenv("OLLAMA_HOST")
+	}
+	if host == "" {
+		host = DefaultOllamaHost
+	}
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	return strings.TrimRight(host, "/")
+}
+
+// Model returns the name of the model used for generation
+func (o *OllamaProvider) Model() string {
+	return o.model
+}
+
+func (o *OllamaProvider) GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error) {
+	return generateStructured(ctx, o, req)
+}
+
+// complete runs a chat completion against /api/chat, passing the schema as Ollama's structured output format
+func (o *OllamaProvider) complete(ctx context.Context, req chatRequest) (string, error) {
+	payload := map[string]interface{}{
+		"model":    o.model,
+		"messages": chatMessagesPayload(req.Messages),
+		"stream":   false,
+	}
+	if req.Schema != nil {
+		payload["format"] = req.Schema
+	}
+	if options := o.modelOptions(); len(options) > 0 {
+		payload["options"] = options
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode ollama request: %v", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", o.host+"/api/chat", bytes.NewBuffer(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create ollama request: %v", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.opts.client().Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", ollamaUnreachable(o.host, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read ollama response: %v", err)
+	}
+
+	var parsed struct {
+		Message struct {
+			Content string `json:"content"`
+			// Thinking holds the reasoning of thinking models when the daemon separates it
+			Thinking string `json:"thinking"`
+		} `json:"message"`
+		PromptEvalCount int `json:"prompt_eval_count"`
+		EvalCount       int `json:"eval_count"`
+	}
+	_ = json.Unmarshal(data, &parsed)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("ollama model %q is not installed (run `ollama pull %s`)", o.model, o.model)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIError("ollama", resp.StatusCode, resp.Header, data)
+	}
+	recordUsage(ctx, "ollama", o.model, parsed.PromptEvalCount, parsed.EvalCount, messagesText(req.Messages), parsed.Message.Content)
+	if parsed.Message.Content == "" {
+		return "", fmt.Errorf("no response content returned from ollama")
+	}
+	answer := cleanOutput(ctx, "ollama", o.model, parsed.Message.Content, parsed.Message.Thinking)
+	if answer == "" {
+		return "", fmt.Errorf("ollama returned only reasoning and no answer; the model may have run out of output tokens")
+	}
+
+	return answer, nil
+}
+
+// contextWindow reports the context size the daemon runs the model with, which
+// is usually smaller than what the model itself supports
+func (o *OllamaProvider) contextWindow() int {
+	return ollamaContextWindow
+}
+
+// modelOptions maps the sampling parameters to Ollama's model options
+func (o *OllamaProvider) modelOptions() map[string]interface{} {
+	options := map[string]interface{}{}
+	if o.opts.Temperature != nil {
+		options["temperature"] = *o.opts.Temperature
+	}
+	if o.opts.TopP != nil {
+		options["top_p"] = *o.opts.TopP
+	}
+	if o.opts.MaxTokens > 0 {
+		options["num_predict"] = o.opts.MaxTokens
+	}
+	return options
+}
+
+// ListOllamaModels returns the names of the models installed in the Ollama daemon at host
+func ListOllamaModels(host string) ([]string, error) {
+	host = OllamaHost(host)
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	resp, err := client.Get(host + "/api/tags")
+	if err != nil {
+		return nil, ollamaUnreachable(host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama error (%d) while listing models", resp.StatusCode)
+	}
+
+	var parsed struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse ollama model list: %v", err)
+	}
+
+	models := make([]string, 0, len(parsed.Models))
+	for _, m := range parsed.Models {
+		models = append(models, m.Name)
+	}
+	return models, nil
+}
+
+// ollamaUnreachable wraps a connection error with a hint on starting the daemon
+func ollamaUnreachable(host string, err error) error {
+	return fmt.Errorf("%w at %s (start it with `ollama serve`): %v", ErrOllamaNotRunning, host, err)
+}
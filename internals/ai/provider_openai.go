@@ -2,40 +2,192 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"strings"
 
-	"github.com/kurtiz/commit-feed/internals/git"
 	openai "github.com/sashabaranov/go-openai"
 )
 
 type OpenAIProvider struct {
 	client *openai.Client
+	model  string
+	opts   Options
 }
 
-func NewOpenAIProvider(apiKey string) *OpenAIProvider {
+func NewOpenAIProvider(opts Options) *OpenAIProvider {
+	apiKey := opts.APIKey
 	if apiKey == "" {
 		apiKey = os.Getenv("OPENAI_API_KEY")
 	}
-	client := openai.NewClient(apiKey)
-	return &OpenAIProvider{client: client}
+	config := openai.DefaultConfig(apiKey)
+	config.HTTPClient = opts.client()
+	client := openai.NewClientWithConfig(config)
+	return &OpenAIProvider{client: client, model: opts.modelOr("gpt-4o-mini"), opts: opts}
+}
+
+// Model returns the name of the model used for generation
+func (p *OpenAIProvider) Model() string {
+	return p.model
 }
 
 // GeneratePosts uses OpenAI to generate platform-specific posts.
-func (p *OpenAIProvider) GeneratePosts(commits []git.Commit, platforms []string, projectContext string) (*GeneratedPosts, error) {
-	prompt := buildPrompt(commits, platforms, projectContext)
-
-	resp, err := p.client.CreateChatCompletion(context.TODO(), openai.ChatCompletionRequest{
-		Model: "gpt-4o-mini",
-		Messages: []openai.ChatCompletionMessage{
-			{Role: "system", Content: "You are CommitFeed, a social media assistant for developers."},
-			{Role: "user", Content: prompt},
-		},
-	})
+func (p *OpenAIProvider) GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error) {
+	return generateStructured(ctx, p, req)
+}
+
+// StreamPosts generates posts like GeneratePosts, reporting post text as it streams in
+func (p *OpenAIProvider) StreamPosts(ctx context.Context, req Request, onDelta func(key, text string)) (*GeneratedPosts, error) {
+	return streamStructured(ctx, p, req, onDelta)
+}
+
+// complete runs a chat completion, using OpenAI structured outputs when a schema is requested
+func (p *OpenAIProvider) complete(ctx context.Context, req chatRequest) (string, error) {
+	resp, err := p.client.CreateChatCompletion(ctx, p.request(req))
 	if err != nil {
-		return nil, fmt.Errorf("openai error: %v", err)
+		return "", openAIError(err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from openai")
+	}
+	recordUsage(ctx, "openai", p.model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, messagesText(req.Messages), resp.Choices[0].Message.Content)
+	if resp.Choices[0].FinishReason == openai.FinishReasonContentFilter {
+		return "", errOpenAIContentFilter
 	}
 
-	content := resp.Choices[0].Message.Content
-	return parseResponse(content), nil
+	msg := resp.Choices[0].Message
+	return cleanOutput(ctx, "openai", p.model, msg.Content, msg.ReasoningContent), nil
+}
+
+// completeN runs a chat completion that returns req.N choices
+func (p *OpenAIProvider) completeN(ctx context.Context, req chatRequest) ([]string, error) {
+	resp, err := p.client.CreateChatCompletion(ctx, p.request(req))
+	if err != nil {
+		return nil, openAIError(err)
+	}
+
+	var raw, outputs []string
+	for _, choice := range resp.Choices {
+		if choice.FinishReason != openai.FinishReasonContentFilter && choice.Message.Content != "" {
+			raw = append(raw, choice.Message.Content)
+			outputs = append(outputs, cleanOutput(ctx, "openai", p.model, choice.Message.Content, choice.Message.ReasoningContent))
+		}
+	}
+	recordUsage(ctx, "openai", p.model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, messagesText(req.Messages), raw...)
+	if len(outputs) == 0 && len(resp.Choices) > 0 {
+		return nil, errOpenAIContentFilter
+	}
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("no response from openai")
+	}
+	return outputs, nil
+}
+
+// stream runs a streaming chat completion
+func (p *OpenAIProvider) stream(ctx context.Context, req chatRequest, onChunk func(string)) (string, error) {
+	request := p.request(req)
+	request.Stream = true
+	request.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+
+	stream, err := p.client.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		return "", openAIError(err)
+	}
+	defer stream.Close()
+
+	filter := newReasoningFilter(p.model, onChunk)
+	var content, reasoning strings.Builder
+	var usage openai.Usage
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", openAIError(err)
+		}
+		if resp.Usage != nil {
+			usage = *resp.Usage
+		}
+		if len(resp.Choices) == 0 {
+			continue
+		}
+		if resp.Choices[0].FinishReason == openai.FinishReasonContentFilter {
+			return "", errOpenAIContentFilter
+		}
+		reasoning.WriteString(resp.Choices[0].Delta.ReasoningContent)
+		if chunk := resp.Choices[0].Delta.Content; chunk != "" {
+			content.WriteString(chunk)
+			filter.Write(chunk)
+		}
+	}
+	recordUsage(ctx, "openai", p.model, usage.PromptTokens, usage.CompletionTokens, messagesText(req.Messages), content.String())
+	if content.Len() == 0 {
+		return "", fmt.Errorf("no response from openai")
+	}
+
+	return cleanOutput(ctx, "openai", p.model, content.String(), reasoning.String()), nil
+}
+
+// request builds the chat completion request
+func (p *OpenAIProvider) request(req chatRequest) openai.ChatCompletionRequest {
+	request := openai.ChatCompletionRequest{
+		Model:               p.model,
+		MaxCompletionTokens: p.opts.MaxTokens,
+	}
+	if p.opts.Temperature != nil {
+		request.Temperature = nonZero(*p.opts.Temperature)
+	}
+	if p.opts.TopP != nil {
+		request.TopP = nonZero(*p.opts.TopP)
+	}
+	if req.N > 1 {
+		request.N = req.N
+	}
+	for _, m := range req.Messages {
+		request.Messages = append(request.Messages, openai.ChatCompletionMessage{Role: m.Role, Content: m.Content})
+	}
+	if req.Schema != nil {
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   "commitfeed_posts",
+				Schema: req.Schema,
+				Strict: true,
+			},
+		}
+	}
+	return request
+}
+
+var errOpenAIContentFilter = &APIError{Provider: "openai", Kind: ErrContentFiltered, Message: "the response was blocked by OpenAI's content filter"}
+
+// openAIError converts go-openai errors into typed API errors
+func openAIError(err error) error {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		body := fmt.Sprintf("%v %s %s", apiErr.Code, apiErr.Type, apiErr.Message)
+		typed := newAPIError("openai", apiErr.HTTPStatusCode, nil, []byte(body))
+		typed.Message = apiErr.Message
+		return typed
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return newAPIError("openai", reqErr.HTTPStatusCode, nil, reqErr.Body)
+	}
+	return fmt.Errorf("openai error: %w", err)
+}
+
+// nonZero returns v, or the smallest positive float32 if v is 0. go-openai
+// leaves zero-valued sampling parameters out of the request, so the API would
+// use its default instead of e.g. --temperature 0; the tiny value it sends
+// instead samples the same way.
+func nonZero(v float32) float32 {
+	if v == 0 {
+		return math.SmallestNonzeroFloat32
+	}
+	return v
 }
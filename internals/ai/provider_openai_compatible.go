@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Response formats an OpenAI-compatible server can be asked for
+const (
+	ResponseFormatJSONSchema = "json_schema" // output matching a strict JSON schema (the default)
+	ResponseFormatJSONObject = "json_object" // any JSON object; the schema is enforced by validation
+	ResponseFormatNone       = "none"        // no response_format, for servers that reject it
+)
+
+// OpenAICompatibleProvider talks to any server exposing an OpenAI-style
+// /chat/completions endpoint (vLLM, llama.cpp server, LM Studio, LiteLLM, internal gateways)
+type OpenAICompatibleProvider struct {
+	endpoint chatEndpoint
+	model    string
+	opts     Options
+}
+
+// NewOpenAICompatibleProvider creates a provider for the server at opts.BaseURL
+// (e.g. http://localhost:8000/v1). BaseURL and Model are required.
+func NewOpenAICompatibleProvider(opts Options) (*OpenAICompatibleProvider, error) {
+	if opts.BaseURL == "" {
+		return nil, fmt.Errorf("openai-compatible provider requires a base_url")
+	}
+	if opts.Model == "" {
+		return nil, fmt.Errorf("openai-compatible provider requires a model")
+	}
+	switch strings.ToLower(opts.AuthScheme) {
+	case "", AuthBearer, AuthAPIKey, AuthNone:
+	default:
+		return nil, fmt.Errorf("unknown auth scheme %q (use %s, %s or %s)", opts.AuthScheme, AuthBearer, AuthAPIKey, AuthNone)
+	}
+	switch strings.ToLower(opts.ResponseFormat) {
+	case "", ResponseFormatJSONSchema, ResponseFormatJSONObject, ResponseFormatNone:
+	default:
+		return nil, fmt.Errorf("unknown response format %q (use %s, %s or %s)", opts.ResponseFormat, ResponseFormatJSONSchema, ResponseFormatJSONObject, ResponseFormatNone)
+	}
+
+	return &OpenAICompatibleProvider{
+		endpoint: chatEndpoint{
+			name:       "openai-compatible",
+			url:        strings.TrimRight(opts.BaseURL, "/") + "/chat/completions",
+			apiKey:     opts.APIKey,
+			authScheme: opts.AuthScheme,
+			headers:    opts.Headers,
+			client:     opts.HTTPClient,
+		},
+		model: opts.Model,
+		opts:  opts,
+	}, nil
+}
+
+// Model returns the name of the model used for generation
+func (o *OpenAICompatibleProvider) Model() string {
+	return o.model
+}
+
+func (o *OpenAICompatibleProvider) GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error) {
+	return generateStructured(ctx, o, req)
+}
+
+// StreamPosts generates posts like GeneratePosts, reporting post text as it streams in
+func (o *OpenAICompatibleProvider) StreamPosts(ctx context.Context, req Request, onDelta func(key, text string)) (*GeneratedPosts, error) {
+	return streamStructured(ctx, o, req, onDelta)
+}
+
+// complete runs a chat completion, requesting structured output when a schema is given
+func (o *OpenAICompatibleProvider) complete(ctx context.Context, req chatRequest) (string, error) {
+	return o.endpoint.complete(ctx, o.payload(req))
+}
+
+// stream runs a streaming chat completion
+func (o *OpenAICompatibleProvider) stream(ctx context.Context, req chatRequest, onChunk func(string)) (string, error) {
+	return o.endpoint.stream(ctx, o.payload(req), onChunk)
+}
+
+// payload builds the chat completion request body
+func (o *OpenAICompatibleProvider) payload(req chatRequest) map[string]interface{} {
+	body := map[string]interface{}{
+		"model":    o.model,
+		"messages": chatMessagesPayload(req.Messages),
+	}
+	applyChatParams(body, o.opts)
+	if req.Schema != nil {
+		switch strings.ToLower(o.opts.ResponseFormat) {
+		case ResponseFormatJSONObject:
+			body["response_format"] = map[string]string{"type": "json_object"}
+		case ResponseFormatNone:
+		default:
+			body["response_format"] = jsonSchemaResponseFormat(req.Schema)
+		}
+	}
+
+	return body
+}
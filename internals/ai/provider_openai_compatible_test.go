@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestOpenAICompatibleResponseFormat(t *testing.T) {
+	content, _ := json.Marshal(`{"posts": {"linkedin": "Widgetly now exports to CSV.", "twitter": "CSV export is here."}}`)
+	body := `{"choices": [{"index": 0, "message": {"role": "assistant", "content": ` + string(content) + `}, "finish_reason": "stop"}]}`
+
+	tests := []struct {
+		format string
+		want   interface{} // nil when no response_format is sent
+	}{
+		{"", "json_schema"},
+		{"json_schema", "json_schema"},
+		{"JSON_OBJECT", map[string]interface{}{"type": "json_object"}},
+		{"none", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			srv, _, payload := grokServer(t, http.StatusOK, nil, body)
+			p, err := NewOpenAICompatibleProvider(Options{
+				BaseURL:        srv.URL + "/v1",
+				Model:          "qwen2.5",
+				ResponseFormat: tt.format,
+				HTTPClient:     srv.Client(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := p.GeneratePosts(context.Background(), replayRequest()); err != nil {
+				t.Fatalf("GeneratePosts: %v", err)
+			}
+
+			got, sent := (*payload)["response_format"]
+			switch want := tt.want.(type) {
+			case nil:
+				if sent {
+					t.Errorf("sent response_format %v", got)
+				}
+			case string:
+				format, _ := got.(map[string]interface{})
+				schema, _ := format["json_schema"].(map[string]interface{})
+				if format["type"] != want || schema["strict"] != true || schema["schema"] == nil {
+					t.Errorf("response_format = %v, want a strict %s", got, want)
+				}
+			default:
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("response_format = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAICompatibleUnknownResponseFormat(t *testing.T) {
+	_, err := NewOpenAICompatibleProvider(Options{BaseURL: "http://localhost:8000/v1", Model: "qwen2.5", ResponseFormat: "xml"})
+	if err == nil || err.Error() != `unknown response format "xml" (use json_schema, json_object or none)` {
+		t.Errorf("got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package ai
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOpenAIRequestKeepsZeroSampling(t *testing.T) {
+	zero := float32(0)
+	tests := []struct {
+		name  string
+		opts  Options
+		field string
+		sent  bool
+	}{
+		{"temperature 0", Options{Temperature: &zero}, "temperature", true},
+		{"top_p 0", Options{TopP: &zero}, "top_p", true},
+		{"temperature unset", Options{}, "temperature", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.APIKey = "test-key"
+			body, err := json.Marshal(NewOpenAIProvider(tt.opts).request(chatRequest{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]interface{}
+			json.Unmarshal(body, &fields)
+			v, ok := fields[tt.field]
+			if ok != tt.sent {
+				t.Fatalf("%s sent = %v, want %v: %s", tt.field, ok, tt.sent, body)
+			}
+			if ok && v.(float64) > 1e-6 {
+				t.Errorf("%s = %v, want about 0", tt.field, v)
+			}
+		})
+	}
+}
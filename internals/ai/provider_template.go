@@ -0,0 +1,366 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kurtiz/commit-feed/internals/git"
+)
+
+// TemplateProviderName is the name the rule-based provider is configured and reported by
+const TemplateProviderName = "template"
+
+// TemplateProvider writes posts straight from the commits with the "offline"
+// text templates instead of a model, so it works without an API key or a
+// network. Posts are written in English whatever language was requested.
+type TemplateProvider struct{}
+
+func NewTemplateProvider() *TemplateProvider {
+	return &TemplateProvider{}
+}
+
+// OfflineData is the data the offline templates are rendered with
+type OfflineData struct {
+	Platform PlatformInfo
+	Project  string
+	// Summary counts the changes by kind, e.g. "2 new features and 3 fixes"
+	Summary    string
+	Sections   []OfflineSection
+	Highlights []string
+	Hashtags   []string
+	Stats      PromptStats
+}
+
+// OfflineSection is a group of changes of one conventional commit type
+type OfflineSection struct {
+	// Type is the conventional commit type, or "" for everything else
+	Type  string
+	Title string
+	Items []string
+}
+
+// changeKinds are the kinds of change posts mention, most important first.
+// Commits of other types (chore, ci, test, ...) fall under the last one.
+var changeKinds = []struct {
+	Type, Title, One, Many string
+}{
+	{"feat", "New features", "new feature", "new features"},
+	{"fix", "Fixes", "fix", "fixes"},
+	{"perf", "Performance", "performance improvement", "performance improvements"},
+	{"refactor", "Refactoring", "refactor", "refactors"},
+	{"docs", "Documentation", "documentation update", "documentation updates"},
+	{"", "Other changes", "other change", "other changes"},
+}
+
+// maxHighlights is how many changes a post mentions at most, per platform
+var maxHighlights = map[string]int{
+	"linkedin": 6,
+	"twitter":  3,
+	"bluesky":  3,
+	"mastodon": 4,
+	"devto":    3,
+	"reddit":   5,
+}
+
+// maxThreadHighlights is how many changes a thread mentions at most
+const maxThreadHighlights = 12
+
+// hashtagLimits is how many hashtags a post gets, per platform; 0 means none
+var hashtagLimits = map[string]int{
+	"linkedin": 4,
+	"twitter":  2,
+	"bluesky":  2,
+	"mastodon": 3,
+}
+
+// hashtagKeywords turn topics mentioned in the project context into hashtags
+var hashtagKeywords = []struct {
+	pattern *regexp.Regexp
+	tag     string
+}{
+	{regexp.MustCompile(`(?i:\bgolang\b)|\bGo\b`), "#golang"},
+	{regexp.MustCompile(`(?i)\brust\b`), "#rustlang"},
+	{regexp.MustCompile(`(?i)\bpython\b`), "#python"},
+	{regexp.MustCompile(`(?i)\btypescript\b`), "#typescript"},
+	{regexp.MustCompile(`(?i)\bjavascript\b`), "#javascript"},
+	{regexp.MustCompile(`(?i)\bnode\.?js\b`), "#nodejs"},
+	{regexp.MustCompile(`(?i)\breact\b`), "#react"},
+	{regexp.MustCompile(`(?i)\b(kubernetes|k8s)\b`), "#kubernetes"},
+	{regexp.MustCompile(`(?i)\bdocker\b`), "#docker"},
+	{regexp.MustCompile(`(?i)\blinux\b`), "#linux"},
+	{regexp.MustCompile(`\bCLI\b|(?i:\bcommand[- ]line\b)`), "#CLI"},
+	{regexp.MustCompile(`\bAPIs?\b`), "#API"},
+	{regexp.MustCompile(`\bAI\b|(?i:\b(LLMs?|machine learning)\b)`), "#AI"},
+	{regexp.MustCompile(`(?i)\bopen[- ]source\b`), "#OpenSource"},
+	{regexp.MustCompile(`(?i)\bdevops\b`), "#DevOps"},
+	{regexp.MustCompile(`(?i)\b(developer tools|devtools)\b`), "#DevTools"},
+	{regexp.MustCompile(`(?i)\bgit\b`), "#git"},
+}
+
+// ticketPrefix matches a leading ticket reference such as "[ABC-123] "
+var ticketPrefix = regexp.MustCompile(`^\[[^\]]*\]\s*`)
+
+// change is a commit reduced to what a post says about it
+type change struct {
+	kind     int // index into changeKinds
+	text     string
+	breaking bool
+}
+
+// GeneratePosts fills the offline templates for every target. Each post
+// mentions as many of the most important changes as fit its platform's limit.
+func (t *TemplateProvider) GeneratePosts(ctx context.Context, req Request) (*GeneratedPosts, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	templates := req.Templates
+	if templates == nil {
+		templates = DefaultTemplates()
+	}
+
+	changes := commitChanges(req.Commits)
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("no changes to write about in the commits")
+	}
+
+	base := promptData(req)
+	project := projectName(req.ProjectContext)
+	tags := projectHashtags(project, req.ProjectContext)
+	summary := changeSummary(changes)
+
+	targets := req.Targets()
+	posts := &GeneratedPosts{Posts: make(map[string]Post, len(targets))}
+	for _, target := range targets {
+		info := base.Platforms[slices.IndexFunc(base.Platforms, func(p PlatformInfo) bool { return p.Name == target.Platform })]
+		name := "offline post"
+		if info.Thread {
+			name = "offline thread"
+		}
+
+		most, ok := maxHighlights[target.Platform]
+		switch {
+		case info.Thread:
+			most = maxThreadHighlights
+		case !ok:
+			most = 4
+		}
+		data := OfflineData{
+			Platform: info,
+			Project:  project,
+			Summary:  summary,
+			Hashtags: tags[:min(len(tags), hashtagLimits[target.Platform])],
+			Stats:    base.Stats,
+		}
+
+		// Drop the least important changes until the post fits
+		var text string
+		for n := min(most, len(changes)); n > 0; n-- {
+			data.Highlights, data.Sections = pickChanges(changes, n)
+			out, err := templates.execute(name, data)
+			if err != nil {
+				return nil, err
+			}
+			text = strings.TrimSpace(out)
+			if info.Thread || info.Limit == 0 || PostLength(target.Platform, text) <= info.Limit {
+				break
+			}
+		}
+
+		post := Post{Platform: target.Platform, Lang: target.Lang, Text: text}
+		if info.Thread {
+			for _, part := range strings.Split(text, "\n---\n") {
+				if part = strings.TrimSpace(part); part != "" {
+					post.Parts = append(post.Parts, part)
+				}
+			}
+		}
+		posts.Posts[target.Key()] = post
+	}
+
+	// With no model to shorten them, posts still over the limit are truncated
+	if err := fitPosts(ctx, nil, posts, 0); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// VerifyPosts reports no unsupported claims: the posts only repeat the commits
+func (t *TemplateProvider) VerifyPosts(ctx context.Context, req Request, posts *GeneratedPosts) (map[string][]UnsupportedClaim, error) {
+	return nil, ctx.Err()
+}
+
+// commitChanges turns commits into changes, most important first. Merge
+// commits are skipped; breaking changes come before everything else.
+func commitChanges(commits []git.Commit) []change {
+	var changes []change
+	for _, c := range commits {
+		subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+		subject = strings.TrimSpace(ticketPrefix.ReplaceAllString(subject, ""))
+		if subject == "" || strings.HasPrefix(subject, "Merge ") {
+			continue
+		}
+
+		ch := change{kind: len(changeKinds) - 1}
+		if m := conventionalPrefix.FindString(subject); m != "" {
+			typ := conventionalType(subject)
+			for i, k := range changeKinds {
+				if k.Type == typ {
+					ch.kind = i
+				}
+			}
+			ch.breaking = strings.Contains(m, "!")
+			subject = subject[len(m):]
+		}
+		ch.breaking = ch.breaking || strings.Contains(c.Message, "BREAKING CHANGE")
+
+		ch.text = sentenceCase(strings.TrimRight(strings.TrimSpace(subject), "."))
+		if ch.text == "" {
+			continue
+		}
+		if ch.breaking {
+			ch.text += " (breaking change)"
+		}
+		changes = append(changes, ch)
+	}
+
+	slices.SortStableFunc(changes, func(a, b change) int {
+		if a.breaking != b.breaking {
+			if a.breaking {
+				return -1
+			}
+			return 1
+		}
+		return a.kind - b.kind
+	})
+	return changes
+}
+
+// pickChanges returns the n most important changes, as a list and grouped by kind
+func pickChanges(changes []change, n int) ([]string, []OfflineSection) {
+	var highlights []string
+	var sections []OfflineSection
+	for _, ch := range changes[:n] {
+		highlights = append(highlights, ch.text)
+	}
+	for i, kind := range changeKinds {
+		section := OfflineSection{Type: kind.Type, Title: kind.Title}
+		for _, ch := range changes[:n] {
+			if ch.kind == i {
+				section.Items = append(section.Items, ch.text)
+			}
+		}
+		if len(section.Items) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return highlights, sections
+}
+
+// changeSummary counts the changes by kind, e.g. "2 new features, 3 fixes and 1 other change"
+func changeSummary(changes []change) string {
+	counts := make([]int, len(changeKinds))
+	for _, ch := range changes {
+		counts[ch.kind]++
+	}
+
+	var parts []string
+	for i, kind := range changeKinds {
+		switch {
+		case counts[i] == 0:
+			continue
+		case kind.Type == "" && len(parts) == 0:
+			// Without any conventional commits, "other" has nothing to contrast with
+			parts = append(parts, plural(counts[i], "change", "changes"))
+		default:
+			parts = append(parts, plural(counts[i], kind.One, kind.Many))
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, one)
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+// projectName takes the project name from the start of the project context,
+// which is read from the README and usually opens with its title
+func projectName(context string) string {
+	fields := strings.Fields(context)
+	if len(fields) == 0 {
+		return ""
+	}
+	name := strings.TrimRight(fields[0], ":,.-—")
+	first, _ := utf8.DecodeRuneInString(name)
+	switch {
+	case name == "" || utf8.RuneCountInString(name) > 40:
+		return ""
+	case !unicode.IsUpper(first) && !unicode.IsDigit(first):
+		return ""
+	case slices.Contains([]string{"A", "An", "The", "This", "It", "We", "Our"}, name):
+		return ""
+	}
+	return name
+}
+
+// projectHashtags returns a hashtag for the project name followed by ones for
+// the topics the project context mentions, in the order it mentions them
+func projectHashtags(project, context string) []string {
+	var tags []string
+	if tag := hashtag(project); tag != "" {
+		tags = append(tags, tag)
+	}
+
+	// Links (e.g. README badges) say nothing about the project's topics
+	text := urlPattern.ReplaceAllString(context, " ")
+	type found struct {
+		at  int
+		tag string
+	}
+	var matches []found
+	for _, k := range hashtagKeywords {
+		if loc := k.pattern.FindStringIndex(text); loc != nil {
+			matches = append(matches, found{loc[0], k.tag})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b found) int { return a.at - b.at })
+	for _, m := range matches {
+		if !slices.Contains(tags, m.tag) {
+			tags = append(tags, m.tag)
+		}
+	}
+	return tags
+}
+
+// hashtag turns a name into a hashtag, dropping characters hashtags cannot hold
+func hashtag(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			sb.WriteRune(r)
+		}
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "#" + sb.String()
+}
+
+// sentenceCase upper-cases the first letter of s
+func sentenceCase(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
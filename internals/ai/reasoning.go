@@ -0,0 +1,230 @@
+package ai
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// reasoningStyle is how a model marks the reasoning it writes before its answer
+type reasoningStyle int
+
+const (
+	// reasoningPlain models answer directly. A leading <think> block is still
+	// removed, since any model served by a local or OpenAI-compatible runtime
+	// may have been tuned to reason first.
+	reasoningPlain reasoningStyle = iota
+	// reasoningThink models wrap their reasoning in <think> tags. Some chat
+	// templates open the tag in the prompt, so the output may only close it.
+	reasoningThink
+	// reasoningHarmony models (gpt-oss) write OpenAI's harmony format, reasoning
+	// on the "analysis" channel and answering on the "final" one. Servers that
+	// drop the special tokens leave e.g. "analysis...assistantfinal{...}".
+	reasoningHarmony
+)
+
+// reasoningModels maps fragments of model names to their reasoning style,
+// matched case-insensitively in order
+var reasoningModels = []struct {
+	fragment string
+	style    reasoningStyle
+}{
+	{"gpt-oss", reasoningHarmony},
+	{"deepseek-reasoner", reasoningThink},
+	{"deepseek-r1", reasoningThink},
+	{"qwq", reasoningThink},
+	{"qwen3", reasoningThink},
+	{"magistral", reasoningThink},
+	{"phi-4-reasoning", reasoningThink},
+}
+
+const (
+	thinkOpen  = "<think>"
+	thinkClose = "</think>"
+)
+
+var (
+	// harmonyFinal starts the answer of a harmony response, with and without its special tokens
+	harmonyFinal = []string{"<|channel|>final<|message|>", "assistantfinal"}
+	// harmonyEnd ends a harmony message
+	harmonyEnd = regexp.MustCompile(`<\|(?:end|return|call)\|>`)
+	// harmonyMessage matches a harmony message and captures its text
+	harmonyMessage = regexp.MustCompile(`(?s)<\|channel\|>\w+<\|message\|>(.*?)(?:<\|end\|>|$)`)
+)
+
+// reasoningStyleOf returns the reasoning style of a model
+func reasoningStyleOf(model string) reasoningStyle {
+	model = strings.ToLower(model)
+	for _, m := range reasoningModels {
+		if strings.Contains(model, m.fragment) {
+			return m.style
+		}
+	}
+	return reasoningPlain
+}
+
+// stripReasoning splits a model's output into its answer and the reasoning
+// trace written before it. An answer wrapped in a single markdown fence is
+// unwrapped. Output that ends inside the trace, e.g. because the model ran
+// out of tokens while thinking, has an empty answer.
+func stripReasoning(style reasoningStyle, text string) (answer, reasoning string) {
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	if style == reasoningHarmony {
+		for _, marker := range harmonyFinal {
+			if before, after, ok := strings.Cut(text, marker); ok {
+				if loc := harmonyEnd.FindStringIndex(after); loc != nil {
+					after = after[:loc[0]]
+				}
+				return unfence(after), harmonyReasoning(before)
+			}
+		}
+		if harmonyStart(trimmed) {
+			return "", harmonyReasoning(text)
+		}
+	}
+	if rest, ok := strings.CutPrefix(trimmed, thinkOpen); ok {
+		reasoning, answer, _ := strings.Cut(rest, thinkClose)
+		return unfence(answer), strings.TrimSpace(reasoning)
+	}
+	if style == reasoningThink {
+		if reasoning, answer, ok := strings.Cut(text, thinkClose); ok {
+			return unfence(answer), strings.TrimSpace(reasoning)
+		}
+	}
+	return unfence(text), ""
+}
+
+// reasoningPending reports whether streamed output may still be inside a
+// reasoning trace, so that none of it can be shown yet
+func reasoningPending(style reasoningStyle, text string) bool {
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	switch {
+	case strings.HasPrefix(trimmed, thinkOpen):
+		return !strings.Contains(trimmed, thinkClose)
+	case strings.HasPrefix(thinkOpen, trimmed):
+		return true
+	}
+	switch style {
+	case reasoningHarmony:
+		if !harmonyStart(trimmed) && !strings.HasPrefix("<|", trimmed) && !strings.HasPrefix("analysis", trimmed) {
+			return false
+		}
+		for _, marker := range harmonyFinal {
+			if strings.Contains(text, marker) {
+				return false
+			}
+		}
+		return true
+	case reasoningThink:
+		// Without an opening tag, only the start of the answer shows there is no trace
+		answered := strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "```")
+		return !answered && !strings.Contains(text, thinkClose)
+	}
+	return false
+}
+
+// harmonyStart reports whether output starts like a harmony response
+func harmonyStart(text string) bool {
+	return strings.HasPrefix(text, "<|") || strings.HasPrefix(text, "analysis")
+}
+
+// harmonyReasoning returns the text of the harmony messages before the final answer
+func harmonyReasoning(text string) string {
+	if matches := harmonyMessage.FindAllStringSubmatch(text, -1); len(matches) > 0 {
+		parts := make([]string, 0, len(matches))
+		for _, m := range matches {
+			parts = append(parts, strings.TrimSpace(m[1]))
+		}
+		return strings.TrimSpace(strings.Join(parts, "\n\n"))
+	}
+	text = strings.TrimPrefix(strings.TrimSpace(text), "analysis")
+	return strings.TrimSpace(strings.TrimSuffix(text, "assistant"))
+}
+
+// unfence trims an answer and removes a markdown fence wrapped around all of it
+func unfence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") || !strings.HasSuffix(text, "```") || len(text) < 6 {
+		return text
+	}
+	inner := strings.TrimSuffix(text, "```")
+	// Drop the opening fence with its language tag, e.g. ```json
+	if i := strings.IndexByte(inner, '\n'); i >= 0 {
+		return strings.TrimSpace(inner[i+1:])
+	}
+	return strings.TrimSpace(strings.TrimPrefix(inner, "```"))
+}
+
+// reasoningFilter passes streamed output on to onChunk once it is past any
+// reasoning trace, so that only the answer is shown
+type reasoningFilter struct {
+	style   reasoningStyle
+	onChunk func(string)
+	held    strings.Builder
+	passing bool
+}
+
+func newReasoningFilter(model string, onChunk func(string)) *reasoningFilter {
+	return &reasoningFilter{style: reasoningStyleOf(model), onChunk: onChunk}
+}
+
+// Write feeds the next chunk of streamed output to the filter
+func (f *reasoningFilter) Write(chunk string) {
+	if f.passing {
+		f.onChunk(chunk)
+		return
+	}
+	f.held.WriteString(chunk)
+	if reasoningPending(f.style, f.held.String()) {
+		return
+	}
+	f.passing = true
+	if answer, _ := stripReasoning(f.style, f.held.String()); answer != "" {
+		f.onChunk(answer)
+	}
+}
+
+// Reasoning is the reasoning a model wrote before one of its answers
+type Reasoning struct {
+	Provider string
+	Model    string
+	Text     string
+}
+
+// ReasoningLog collects the reasoning traces of the model calls made with a
+// context from WithReasoningLog. It is safe for concurrent use.
+type ReasoningLog struct {
+	mu     sync.Mutex
+	traces []Reasoning
+}
+
+type reasoningLogKey struct{}
+
+// WithReasoningLog returns a context whose model calls keep their reasoning in l
+func WithReasoningLog(ctx context.Context, l *ReasoningLog) context.Context {
+	return context.WithValue(ctx, reasoningLogKey{}, l)
+}
+
+// Traces returns the reasoning collected so far, in the order it was received
+func (l *ReasoningLog) Traces() []Reasoning {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Reasoning(nil), l.traces...)
+}
+
+// cleanOutput removes the reasoning trace from a model's output and keeps it,
+// together with any reasoning the provider returned in a separate field, in
+// the ReasoningLog of ctx
+func cleanOutput(ctx context.Context, provider, model, content, reasoning string) string {
+	answer, trace := stripReasoning(reasoningStyleOf(model), content)
+	if l, _ := ctx.Value(reasoningLogKey{}).(*ReasoningLog); l != nil {
+		if text := strings.TrimSpace(strings.TrimSpace(reasoning) + "\n\n" + trace); text != "" {
+			l.mu.Lock()
+			l.traces = append(l.traces, Reasoning{Provider: provider, Model: model, Text: text})
+			l.mu.Unlock()
+		}
+	}
+	return answer
+}
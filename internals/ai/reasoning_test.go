@@ -0,0 +1,145 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStripReasoning(t *testing.T) {
+	tests := []struct {
+		name      string
+		model     string
+		text      string
+		answer    string
+		reasoning string
+	}{
+		{"plain answer", "llama3.1", `{"posts": {}}`, `{"posts": {}}`, ""},
+		{"plain model thinking anyway", "llama3.1", "\n<think>\nPlan the post.\n</think>\n\n{}", "{}", "Plan the post."},
+		{"fenced answer", "deepseek-r1:8b", "<think>Plan.</think>\n```json\n{\"a\": 1}\n```", `{"a": 1}`, "Plan."},
+		{"opening tag in the prompt", "qwen3:14b", "Plan the post.\n</think>\n{}", "{}", "Plan the post."},
+		{"unclosed think", "deepseek-r1:8b", "<think>Plan the post, then", "", "Plan the post, then"},
+		{"unclosed think on a plain model", "mistral", "<think>Plan the post", "", "Plan the post"},
+		{"only reasoning", "QwQ-32B", "<think>\nHmm.\n</think>", "", "Hmm."},
+		{"think model answering directly", "qwen3:14b", `{"a": 1}`, `{"a": 1}`, ""},
+		{"closing tag on a plain model is kept", "llama3.1", "a </think> b", "a </think> b", ""},
+		{
+			"harmony",
+			"gpt-oss:20b",
+			"<|channel|>analysis<|message|>Plan the post.<|end|><|start|>assistant<|channel|>final<|message|>{}<|return|>",
+			"{}", "Plan the post.",
+		},
+		{
+			"harmony with several messages",
+			"openai/gpt-oss-120b",
+			"<|channel|>analysis<|message|>First.<|end|><|start|>assistant<|channel|>commentary<|message|>Second.<|end|><|start|>assistant<|channel|>final<|message|>{}",
+			"{}", "First.\n\nSecond.",
+		},
+		{"harmony without special tokens", "gpt-oss:20b", "analysisPlan the post.assistantfinal{\"a\": 1}", `{"a": 1}`, "Plan the post."},
+		{"harmony only reasoning", "gpt-oss:20b", "analysisStill planning", "", "Still planning"},
+		{"harmony unfinished", "gpt-oss:20b", "<|channel|>analysis<|message|>Still planning", "", "Still planning"},
+		{"harmony model answering directly", "gpt-oss:20b", `{"a": 1}`, `{"a": 1}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answer, reasoning := stripReasoning(reasoningStyleOf(tt.model), tt.text)
+			if answer != tt.answer {
+				t.Errorf("answer = %q, want %q", answer, tt.answer)
+			}
+			if reasoning != tt.reasoning {
+				t.Errorf("reasoning = %q, want %q", reasoning, tt.reasoning)
+			}
+		})
+	}
+}
+
+func TestReasoningPending(t *testing.T) {
+	tests := []struct {
+		model   string
+		text    string
+		pending bool
+	}{
+		{"llama3.1", "", true},
+		{"llama3.1", "  <thi", true},
+		{"llama3.1", "<think>Plan", true},
+		{"llama3.1", "<think>Plan</think>", false},
+		{"llama3.1", "{", false},
+		{"llama3.1", "Sure", false},
+		{"deepseek-r1", "Plan the", true},
+		{"deepseek-r1", "Plan</think>", false},
+		{"deepseek-r1", "{\"posts\"", false},
+		{"deepseek-r1", "```json", false},
+		{"gpt-oss:20b", "ana", true},
+		{"gpt-oss:20b", "<|chan", true},
+		{"gpt-oss:20b", "analysisPlan.assistant", true},
+		{"gpt-oss:20b", "analysisPlan.assistantfinal", false},
+		{"gpt-oss:20b", "<|channel|>analysis<|message|>Plan<|end|><|start|>assistant<|channel|>final<|message|>", false},
+		{"gpt-oss:20b", "{", false},
+	}
+	for _, tt := range tests {
+		if got := reasoningPending(reasoningStyleOf(tt.model), tt.text); got != tt.pending {
+			t.Errorf("reasoningPending(%s, %q) = %v, want %v", tt.model, tt.text, got, tt.pending)
+		}
+	}
+}
+
+func TestReasoningFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		model  string
+		chunks []string
+		want   string
+	}{
+		{"plain answer", "llama3.1", []string{`{"a"`, `: 1}`}, `{"a": 1}`},
+		{"tags split across chunks", "llama3.1", []string{"\n", "<thi", "nk>Plan</thi", "nk>\n{\"a\"", ": 1}"}, `{"a": 1}`},
+		{"opening tag in the prompt", "deepseek-r1:8b", []string{"Plan ", "more</th", "ink>\n{}"}, "{}"},
+		{"unclosed think", "deepseek-r1:8b", []string{"<think>Plan", " more"}, ""},
+		{"only reasoning", "qwq", []string{"<think>Plan", "</think>"}, ""},
+		{
+			"harmony split in a marker",
+			"gpt-oss:20b",
+			[]string{"<|channel|>analysis<|message|>Plan<|end|><|start|>assistant<|channel|>fin", "al<|message|>{\"a\":", " 1}"},
+			`{"a": 1}`,
+		},
+		{"harmony without special tokens", "gpt-oss:20b", []string{"analysis", "Plan.", "assistant", "final", "{}"}, "{}"},
+		{"harmony only reasoning", "gpt-oss:20b", []string{"analysis", "Still planning"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got strings.Builder
+			f := newReasoningFilter(tt.model, func(chunk string) { got.WriteString(chunk) })
+			for _, c := range tt.chunks {
+				f.Write(c)
+			}
+			if got.String() != tt.want {
+				t.Errorf("streamed %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanOutputLogsReasoning(t *testing.T) {
+	var log ReasoningLog
+	ctx := WithReasoningLog(context.Background(), &log)
+
+	if answer := cleanOutput(ctx, "ollama", "deepseek-r1:8b", "<think>From the tags.</think>{}", "From the field."); answer != "{}" {
+		t.Errorf("answer = %q", answer)
+	}
+	if answer := cleanOutput(ctx, "ollama", "llama3.1", "{}", ""); answer != "{}" {
+		t.Errorf("answer = %q", answer)
+	}
+
+	traces := log.Traces()
+	if len(traces) != 1 {
+		t.Fatalf("got %d traces, want only the one with reasoning", len(traces))
+	}
+	want := Reasoning{Provider: "ollama", Model: "deepseek-r1:8b", Text: "From the field.\n\nFrom the tags."}
+	if traces[0] != want {
+		t.Errorf("trace = %+v, want %+v", traces[0], want)
+	}
+
+	// Without a log the reasoning is dropped
+	if answer := cleanOutput(context.Background(), "ollama", "qwen3", "Plan</think>{}", ""); answer != "{}" {
+		t.Errorf("answer = %q", answer)
+	}
+}
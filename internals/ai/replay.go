@@ -0,0 +1,204 @@
+package ai
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNoFixture is returned when a replayed request has no recorded response
+var ErrNoFixture = errors.New("no recorded response")
+
+// fixtureHeaders are the response headers kept in fixtures. Everything else
+// (dates, request IDs, cookies) changes from one recording to the next.
+var fixtureHeaders = []string{"Content-Type", "Retry-After"}
+
+// Fixture is one recorded HTTP exchange, stored as a golden file. Request
+// headers are never stored, so API keys stay out of the fixtures.
+type Fixture struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody fixtureBody `json:"request_body,omitempty"`
+
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    fixtureBody       `json:"body"`
+}
+
+// fixtureBody is a body as stored in a fixture: inline when it is JSON, so
+// fixtures can be read and diffed, and as a string otherwise (e.g. streams)
+type fixtureBody string
+
+func (b fixtureBody) MarshalJSON() ([]byte, error) {
+	if b != "" && json.Valid([]byte(b)) {
+		return []byte(b), nil
+	}
+	return json.Marshal(string(b))
+}
+
+func (b *fixtureBody) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*b = fixtureBody(s)
+		return nil
+	}
+	*b = fixtureBody(data)
+	return nil
+}
+
+// ReplayTransport serves HTTP responses from fixtures in Dir, one file per
+// distinct request, so providers can run without a network. With Record set it
+// sends requests on to Base and writes the responses as fixtures instead.
+type ReplayTransport struct {
+	Dir    string
+	Record bool
+	// Base sends requests while recording; nil uses http.DefaultTransport
+	Base http.RoundTripper
+}
+
+// NewReplayClient returns an HTTP client that replays, or records, the
+// fixtures in dir. Pass it to providers as Options.HTTPClient.
+func NewReplayClient(dir string, record bool) *http.Client {
+	return &http.Client{
+		Timeout:   5 * time.Minute,
+		Transport: &ReplayTransport{Dir: dir, Record: record},
+	}
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	path := filepath.Join(t.Dir, fixtureName(req, body))
+
+	if t.Record {
+		return t.record(req, body, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w for %s %s (expected %s)", ErrNoFixture, req.Method, fixtureURL(req), path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %v", err)
+	}
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %v", path, err)
+	}
+	return f.response(req), nil
+}
+
+// record sends req on and saves the response as a fixture
+func (t *ReplayTransport) record(req *http.Request, body []byte, path string) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	f := Fixture{
+		Method:      req.Method,
+		URL:         fixtureURL(req),
+		RequestBody: fixtureBody(body),
+		Status:      resp.StatusCode,
+		Body:        fixtureBody(data),
+	}
+	for _, name := range fixtureHeaders {
+		if v := resp.Header.Get(name); v != "" {
+			if f.Headers == nil {
+				f.Headers = make(map[string]string)
+			}
+			f.Headers[name] = v
+		}
+	}
+
+	out, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create fixture directory: %v", err)
+	}
+	if err := os.WriteFile(path, append(out, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write fixture: %v", err)
+	}
+	return resp, nil
+}
+
+// response rebuilds the recorded response for req
+func (f Fixture) response(req *http.Request) *http.Response {
+	header := make(http.Header, len(f.Headers))
+	for name, v := range f.Headers {
+		header.Set(name, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(string(f.Body))),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}
+}
+
+// fixtureName names the fixture of a request after its host and a hash of
+// its method, URL and body. JSON bodies are hashed in canonical form, so
+// the order fields are sent in does not matter.
+func fixtureName(req *http.Request, body []byte) string {
+	var v any
+	if json.Unmarshal(body, &v) == nil {
+		if canonical, err := json.Marshal(v); err == nil {
+			body = canonical
+		}
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", req.Method, fixtureURL(req))
+	h.Write(body)
+
+	host := strings.NewReplacer(":", "_", "/", "_").Replace(req.URL.Host)
+	return fmt.Sprintf("%s-%s.json", host, hex.EncodeToString(h.Sum(nil))[:16])
+}
+
+// fixtureURL returns the request URL without credentials passed in the query
+func fixtureURL(req *http.Request) string {
+	u := *req.URL
+	q := u.Query()
+	if q.Has("key") {
+		q.Del("key")
+		u.RawQuery = q.Encode()
+	}
+	u.User = nil
+	return u.String()
+}
@@ -0,0 +1,206 @@
+package ai
+
+import (
+	"context"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// StreamingProvider is implemented by providers that can stream posts while
+// they are generated. onDelta receives each new piece of a post's text with
+// the post's key (see Target.Key); the returned posts are the same validated result GeneratePosts returns.
+type StreamingProvider interface {
+	Provider
+	StreamPosts(ctx context.Context, req Request, onDelta func(key, text string)) (*GeneratedPosts, error)
+}
+
+// chatStreamer is implemented by providers that can stream a chat completion
+type chatStreamer interface {
+	chatCompleter
+	stream(ctx context.Context, req chatRequest, onChunk func(string)) (string, error)
+}
+
+// streamStructured streams the first attempt through a postStreamDecoder so
+// post text can be shown as it arrives. Repairs, if needed, are not streamed,
+// and neither are requests for several variants or threads.
+func streamStructured(ctx context.Context, s chatStreamer, req Request, onDelta func(key, text string)) (*GeneratedPosts, error) {
+	if req.Variants > 1 || req.Thread {
+		return generateStructured(ctx, s, req)
+	}
+	decoder := newPostStreamDecoder(onDelta)
+	first := func(ctx context.Context, req chatRequest) (string, error) {
+		return s.stream(ctx, req, decoder.Write)
+	}
+	return generateWith(ctx, s, first, req)
+}
+
+// postStreamDecoder incrementally scans a JSON document of the form
+// {"posts": {"<target>": "<text>"}} and reports the decoded text of each
+// post as it arrives, without waiting for the document to be complete
+type postStreamDecoder struct {
+	onDelta func(key, text string)
+
+	started  bool
+	stack    []streamFrame
+	inString bool
+	isKey    bool
+	escape   bool
+	hex      []byte // pending \uXXXX digits
+	surr     rune   // pending high surrogate
+	key      []byte // key being read
+	platform string // key of the post being read, if any
+	pending  []byte // decoded post text not yet reported
+}
+
+type streamFrame struct {
+	object    bool
+	expectKey bool
+	key       string
+}
+
+func newPostStreamDecoder(onDelta func(key, text string)) *postStreamDecoder {
+	return &postStreamDecoder{onDelta: onDelta}
+}
+
+// Write feeds the next chunk of model output to the decoder
+func (d *postStreamDecoder) Write(chunk string) {
+	for i := 0; i < len(chunk); i++ {
+		d.feed(chunk[i])
+	}
+	d.flush(false)
+}
+
+func (d *postStreamDecoder) feed(b byte) {
+	// Skip any preamble or markdown fence before the JSON object
+	if !d.started {
+		if b == '{' {
+			d.started = true
+			d.stack = append(d.stack, streamFrame{object: true, expectKey: true})
+		}
+		return
+	}
+	if len(d.stack) == 0 {
+		return
+	}
+	if d.inString {
+		d.feedString(b)
+		return
+	}
+
+	top := &d.stack[len(d.stack)-1]
+	switch b {
+	case '"':
+		d.inString = true
+		d.isKey = top.object && top.expectKey
+		d.key = d.key[:0]
+		d.platform = ""
+		if !d.isKey && len(d.stack) == 2 && d.stack[0].key == "posts" && top.object {
+			d.platform = ParseTarget(top.key).Key()
+		}
+	case ':':
+		top.expectKey = false
+	case ',':
+		if top.object {
+			top.expectKey = true
+		}
+	case '{':
+		d.stack = append(d.stack, streamFrame{object: true, expectKey: true})
+	case '[':
+		d.stack = append(d.stack, streamFrame{})
+	case '}', ']':
+		d.stack = d.stack[:len(d.stack)-1]
+	}
+}
+
+func (d *postStreamDecoder) feedString(b byte) {
+	switch {
+	case d.hex != nil:
+		d.hex = append(d.hex, b)
+		if len(d.hex) == 4 {
+			d.decodeUnicode()
+		}
+	case d.escape:
+		d.escape = false
+		switch b {
+		case 'n':
+			d.emit('\n')
+		case 't':
+			d.emit('\t')
+		case 'r':
+			d.emit('\r')
+		case 'b', 'f':
+		case 'u':
+			d.hex = make([]byte, 0, 4)
+		default: // \" \\ \/
+			d.emit(b)
+		}
+	case b == '\\':
+		d.escape = true
+	case b == '"':
+		d.inString = false
+		if d.isKey {
+			d.stack[len(d.stack)-1].key = string(d.key)
+		}
+		d.flush(true)
+		d.platform = ""
+	default:
+		d.emit(b)
+	}
+}
+
+func (d *postStreamDecoder) decodeUnicode() {
+	n, err := strconv.ParseUint(string(d.hex), 16, 32)
+	d.hex = nil
+	if err != nil {
+		return
+	}
+	r := rune(n)
+	switch {
+	case utf16.IsSurrogate(r) && d.surr == 0:
+		d.surr = r
+		return
+	case d.surr != 0:
+		r = utf16.DecodeRune(d.surr, r)
+		d.surr = 0
+	}
+	for _, c := range []byte(string(r)) {
+		d.emit(c)
+	}
+}
+
+func (d *postStreamDecoder) emit(b byte) {
+	if d.isKey {
+		d.key = append(d.key, b)
+		return
+	}
+	if d.platform != "" {
+		d.pending = append(d.pending, b)
+	}
+}
+
+// flush reports the pending post text. Until the post is complete, a
+// character whose bytes have not all arrived is held back for the next chunk.
+func (d *postStreamDecoder) flush(complete bool) {
+	n := len(d.pending)
+	if !complete {
+		n = fullRunes(d.pending)
+	}
+	if n > 0 && d.onDelta != nil {
+		d.onDelta(d.platform, string(d.pending[:n]))
+	}
+	d.pending = append(d.pending[:0], d.pending[n:]...)
+}
+
+// fullRunes returns the length of b without a trailing incomplete UTF-8 character
+func fullRunes(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(b)
+}
@@ -0,0 +1,124 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// streamDocument is model output with a preamble, a fence, a post for a
+// language target and every kind of escape a post may contain
+const streamDocument = "Here you go:\n```json\n" + `{
+  "posts": {
+    "linkedin": "We said \"ship it\" \u2014 and shipped.\nPath: C:\\widgets\\csv",
+    "twitter": "CSV export \ud83d\ude80 is here, caf\u00e9 \/ bar\ttab",
+    "mastodon:fr": "Exporter en CSV — déjà là ✨ 你好"
+  },
+  "notes": {"linkedin": "not a post"},
+  "tags": ["linkedin", "twitter"]
+}` + "\n```"
+
+// streamedPosts feeds chunks to a postStreamDecoder and returns the text it
+// reported for each post
+func streamedPosts(t *testing.T, chunks ...string) map[string]string {
+	t.Helper()
+	got := map[string]*strings.Builder{}
+	d := newPostStreamDecoder(func(key, text string) {
+		if text == "" {
+			t.Errorf("empty delta for %s", key)
+		}
+		if !utf8.ValidString(text) {
+			t.Errorf("delta for %s splits a character: %q", key, text)
+		}
+		if got[key] == nil {
+			got[key] = &strings.Builder{}
+		}
+		got[key].WriteString(text)
+	})
+	for _, c := range chunks {
+		d.Write(c)
+	}
+	posts := make(map[string]string, len(got))
+	for k, b := range got {
+		posts[k] = b.String()
+	}
+	return posts
+}
+
+// wantStreamedPosts decodes the posts of streamDocument with encoding/json
+func wantStreamedPosts(t *testing.T) map[string]string {
+	t.Helper()
+	start, end := strings.Index(streamDocument, "{"), strings.LastIndex(streamDocument, "}")
+	var doc struct {
+		Posts map[string]string `json:"posts"`
+	}
+	if err := json.Unmarshal([]byte(streamDocument[start:end+1]), &doc); err != nil {
+		t.Fatal(err)
+	}
+	want := make(map[string]string, len(doc.Posts))
+	for k, v := range doc.Posts {
+		want[ParseTarget(k).Key()] = v
+	}
+	return want
+}
+
+func TestPostStreamDecoderSplitAnywhere(t *testing.T) {
+	want := wantStreamedPosts(t)
+	for i := 0; i <= len(streamDocument); i++ {
+		got := streamedPosts(t, streamDocument[:i], streamDocument[i:])
+		if !equalPosts(got, want) {
+			t.Fatalf("split at byte %d (%q|%q):\ngot  %q\nwant %q", i, tail(streamDocument[:i]), head(streamDocument[i:]), got, want)
+		}
+	}
+}
+
+func TestPostStreamDecoderByteAtATime(t *testing.T) {
+	var chunks []string
+	for i := 0; i < len(streamDocument); i++ {
+		chunks = append(chunks, streamDocument[i:i+1])
+	}
+	if got, want := streamedPosts(t, chunks...), wantStreamedPosts(t); !equalPosts(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestPostStreamDecoderDeltas(t *testing.T) {
+	var deltas []string
+	d := newPostStreamDecoder(func(key, text string) {
+		deltas = append(deltas, key+"="+text)
+	})
+	for _, c := range []string{`{"posts": {"twitter": "Hel`, `lo \"wor`, `ld\"", "linkedin": "Hi`, ` \u00e`, `9!"}}`} {
+		d.Write(c)
+	}
+	want := []string{`twitter=Hel`, `twitter=lo "wor`, `twitter=ld"`, `linkedin=Hi`, `linkedin= `, `linkedin=é!`}
+	if strings.Join(deltas, "|") != strings.Join(want, "|") {
+		t.Errorf("deltas = %q, want %q", deltas, want)
+	}
+}
+
+func equalPosts(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func head(s string) string {
+	if len(s) > 12 {
+		return s[:12]
+	}
+	return s
+}
+
+func tail(s string) string {
+	if len(s) > 12 {
+		return s[len(s)-12:]
+	}
+	return s
+}
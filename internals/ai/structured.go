@@ -0,0 +1,318 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxRepairAttempts is how many times an invalid response is sent back to the model for repair
+const maxRepairAttempts = 2
+
+const systemPrompt = "You are CommitFeed, a social media assistant for developers. You always answer with a single JSON object and nothing else."
+
+// chatMessage is a single turn in a chat conversation
+type chatMessage struct {
+	Role    string
+	Content string
+}
+
+// chatRequest is a provider-agnostic chat completion request
+type chatRequest struct {
+	Messages []chatMessage
+	// Schema, when set, asks the provider for JSON output matching it
+	Schema *jsonSchema
+	// N, when above 1, asks a multiCompleter for that many choices
+	N int
+}
+
+// chatCompleter is implemented by providers that can run a single chat completion
+type chatCompleter interface {
+	complete(ctx context.Context, req chatRequest) (string, error)
+}
+
+// jsonSchema is a minimal JSON Schema node, enough to describe the expected output
+type jsonSchema struct {
+	Type                 string                 `json:"type"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+}
+
+// MarshalJSON lets the schema be passed anywhere a json.Marshaler is expected
+func (s *jsonSchema) MarshalJSON() ([]byte, error) {
+	type plain jsonSchema
+	return json.Marshal((*plain)(s))
+}
+
+// postsSchema describes the {"posts": {"<target>": "<text>"}} object expected
+// from the model, with a list of parts instead of the text for threads
+func postsSchema(req Request) *jsonSchema {
+	closed := false
+	targets := req.Targets()
+	posts := &jsonSchema{
+		Type:                 "object",
+		Properties:           make(map[string]*jsonSchema, len(targets)),
+		AdditionalProperties: &closed,
+	}
+	for _, t := range targets {
+		posts.Properties[t.Key()] = &jsonSchema{Type: "string"}
+		if req.threaded(t.Platform) {
+			posts.Properties[t.Key()] = &jsonSchema{Type: "array", Items: &jsonSchema{Type: "string"}}
+		}
+		posts.Required = append(posts.Required, t.Key())
+	}
+	return &jsonSchema{
+		Type:                 "object",
+		Properties:           map[string]*jsonSchema{"posts": posts},
+		Required:             []string{"posts"},
+		AdditionalProperties: &closed,
+	}
+}
+
+// InvalidOutputError is returned when the model keeps producing output that
+// does not match the expected schema, even after repair attempts
+type InvalidOutputError struct {
+	Attempts int
+	Output   string
+	Err      error
+}
+
+func (e *InvalidOutputError) Error() string {
+	return fmt.Sprintf("model returned invalid output after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *InvalidOutputError) Unwrap() error {
+	return e.Err
+}
+
+// generateStructured requests posts as JSON and validates them, sending
+// validation errors back to the model for a bounded number of repairs
+func generateStructured(ctx context.Context, c chatCompleter, req Request) (*GeneratedPosts, error) {
+	return generateWith(ctx, c, c.complete, req)
+}
+
+// generateWith prepares the prompt for req and runs the structured loop,
+// once per requested variant, then fits the posts to their platforms' length
+// limits. first performs the initial request of a single generation (e.g.
+// streamed). The token usage of every call is recorded in the posts' Usage.
+func generateWith(ctx context.Context, c chatCompleter, first func(context.Context, chatRequest) (string, error), req Request) (*GeneratedPosts, error) {
+	meter := &UsageMeter{}
+	ctx = WithUsageMeter(ctx, meter)
+	req.Platforms = NormalizePlatforms(req.Platforms)
+	prompt, err := preparePrompt(ctx, c, req)
+	if err != nil {
+		return nil, err
+	}
+
+	chat := chatRequest{
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: prompt},
+		},
+		Schema: postsSchema(req),
+	}
+	var posts *GeneratedPosts
+	if req.Variants > 1 && !req.Thread {
+		posts, err = generateVariants(ctx, c, chat, req)
+	} else {
+		posts, err = structuredLoop(ctx, c, first, chat, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := req.ShortenAttempts
+	if attempts == 0 {
+		attempts = DefaultShortenAttempts
+	}
+	if err := fitPosts(ctx, c, posts, attempts); err != nil {
+		return nil, err
+	}
+	posts.Usage = meter.Usage()
+	return posts, nil
+}
+
+// structuredLoop runs the generate-validate-repair cycle. first performs the
+// initial request (e.g. streamed); repairs always go through c.complete.
+func structuredLoop(ctx context.Context, c chatCompleter, first func(context.Context, chatRequest) (string, error), chat chatRequest, req Request) (*GeneratedPosts, error) {
+	// Repairs extend the conversation; keep them out of the caller's request
+	chat.Messages = slices.Clone(chat.Messages)
+
+	var output string
+	var lastErr error
+	for attempt := 0; attempt <= maxRepairAttempts; attempt++ {
+		call := c.complete
+		if attempt == 0 {
+			call = first
+		}
+		out, err := call(ctx, chat)
+		if err != nil {
+			return nil, err
+		}
+		output = out
+
+		posts, err := validatePosts(out, req)
+		if err == nil {
+			return posts, nil
+		}
+		lastErr = err
+
+		expected := "a non-empty string"
+		if req.Thread {
+			expected += " (a list of non-empty strings for threads)"
+		}
+		var keys []string
+		for _, t := range req.Targets() {
+			keys = append(keys, t.Key())
+		}
+		chat.Messages = append(chat.Messages,
+			chatMessage{Role: "assistant", Content: out},
+			chatMessage{Role: "user", Content: fmt.Sprintf(
+				"Your previous response was invalid: %v\nReply again with ONLY the corrected JSON object, with %s for each of: %s.",
+				err, expected, strings.Join(keys, ", "))},
+		)
+	}
+
+	return nil, &InvalidOutputError{Attempts: maxRepairAttempts + 1, Output: output, Err: lastErr}
+}
+
+// validatePosts parses a JSON response and checks that every requested target has a post
+func validatePosts(text string, req Request) (*GeneratedPosts, error) {
+	raw := extractJSON(text)
+	if raw == "" {
+		return nil, fmt.Errorf("response does not contain a JSON object")
+	}
+
+	var parsed struct {
+		Posts map[string]any `json:"posts"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("response is not valid JSON: %v", err)
+	}
+	if parsed.Posts == nil {
+		return nil, fmt.Errorf(`missing "posts" object`)
+	}
+
+	values := make(map[string]any, len(parsed.Posts))
+	for k, v := range parsed.Posts {
+		values[ParseTarget(k).Key()] = v
+	}
+
+	targets := req.Targets()
+	posts := &GeneratedPosts{Posts: make(map[string]Post, len(targets))}
+	var problems []string
+	for _, t := range targets {
+		key := t.Key()
+		v, ok := values[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("posts.%s is missing", key))
+			continue
+		}
+		if req.threaded(t.Platform) {
+			parts, problem := threadParts(key, v)
+			if problem != "" {
+				problems = append(problems, problem)
+				continue
+			}
+			text := strings.Join(parts, "\n\n")
+			if voice := voiceProblems(req.Voice, key, text); len(voice) > 0 {
+				problems = append(problems, voice...)
+				continue
+			}
+			posts.Posts[key] = Post{Platform: t.Platform, Lang: t.Lang, Text: text, Parts: parts}
+			continue
+		}
+		text, ok := v.(string)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("posts.%s must be a string", key))
+			continue
+		}
+		if strings.TrimSpace(text) == "" {
+			problems = append(problems, fmt.Sprintf("posts.%s is empty", key))
+			continue
+		}
+		if voice := voiceProblems(req.Voice, key, text); len(voice) > 0 {
+			problems = append(problems, voice...)
+			continue
+		}
+		posts.Posts[key] = Post{Platform: t.Platform, Lang: t.Lang, Text: strings.TrimSpace(text)}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return posts, nil
+}
+
+// threadNumbering matches numbering a model may have added at the start or end
+// of a thread part despite being told not to, e.g. "1/3" or "(2/3)"
+var threadNumbering = []*regexp.Regexp{
+	regexp.MustCompile(`^\s*(\()?(\d+)\s*/\s*(\d+)(\))?[.:]?\s*`),
+	regexp.MustCompile(`\s*(\()?(\d+)\s*/\s*(\d+)(\))?\s*$`),
+}
+
+// stripThreadNumbering removes numbering from a part of a thread of count
+// parts. A fraction only counts as numbering when it is parenthesised or
+// numbers the parts there are, so "24/7" or "16/9" in the text is kept.
+func stripThreadNumbering(text string, count int) string {
+	for _, re := range threadNumbering {
+		m := re.FindStringSubmatchIndex(text)
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(text[m[4]:m[5]])
+		total, _ := strconv.Atoi(text[m[6]:m[7]])
+		parenthesised := m[2] >= 0 && m[8] >= 0
+		if (m[2] >= 0) != (m[8] >= 0) || n < 1 || n > total || (!parenthesised && total != count) {
+			continue
+		}
+		text = text[:m[0]] + text[m[1]:]
+	}
+	return text
+}
+
+// threadParts validates the parts of a thread, describing the problem if they are invalid.
+// A single string is accepted as a one-part thread.
+func threadParts(key string, v any) ([]string, string) {
+	var values []any
+	switch v := v.(type) {
+	case string:
+		values = []any{v}
+	case []any:
+		values = v
+	default:
+		return nil, fmt.Sprintf("posts.%s must be a list of strings", key)
+	}
+
+	var parts []string
+	for i, value := range values {
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Sprintf("posts.%s[%d] must be a string", key, i)
+		}
+		text = strings.TrimSpace(stripThreadNumbering(text, len(values)))
+		if text != "" {
+			parts = append(parts, text)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Sprintf("posts.%s is empty", key)
+	}
+	return parts, ""
+}
+
+// extractJSON returns the outermost JSON object in a response, ignoring
+// markdown fences or any text the model added around it
+func extractJSON(text string) string {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return ""
+	}
+	return text[start : end+1]
+}
@@ -0,0 +1,35 @@
+package ai
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestThreadPartsNumbering(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []any
+		want  []string
+	}{
+		{"leading numbers", []any{"1/3 Intro", "2/3. Middle", "3/3: End"}, []string{"Intro", "Middle", "End"}},
+		{"trailing numbers", []any{"Intro 1/2", "End (2/2)"}, []string{"Intro", "End"}},
+		{"parenthesised", []any{"(1/2) Intro", "(2/2) End"}, []string{"Intro", "End"}},
+		{"fraction in the text", []any{"Support now 24/7", "Watch it in 16/9"}, []string{"Support now 24/7", "Watch it in 16/9"}},
+		{"fraction at the start", []any{"24/7 support is here", "3/4 of the work is done"}, []string{"24/7 support is here", "3/4 of the work is done"}},
+		{"numbers for another count", []any{"Half done 1/4", "Still going"}, []string{"Half done 1/4", "Still going"}},
+		{"unbalanced parenthesis", []any{"(1/2 Intro", "End"}, []string{"(1/2 Intro", "End"}},
+		{"fraction inside the text", []any{"We did 1/2 of it", "End"}, []string{"We did 1/2 of it", "End"}},
+		{"only numbering", []any{"1/2", "Text"}, []string{"Text"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, problem := threadParts("twitter", tt.parts)
+			if problem != "" {
+				t.Fatal(problem)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
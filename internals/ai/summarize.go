@@ -0,0 +1,171 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kurtiz/commit-feed/internals/git"
+)
+
+// maxReduceRounds bounds how often chunk summaries are summarized again
+const maxReduceRounds = 3
+
+const summarizeInstructions = `Summarize the following %s into a concise changelog for a release announcement.
+- Use at most about %d words, as bullet points.
+- Group related changes. Keep user-facing features, fixes and notable internal work.
+- Skip merge commits, version bumps and trivial changes.
+- Only mention what the input says; do not invent details.
+Reply with the bullet list only.
+
+--- %s ---
+`
+
+// PromptPlan describes the prompt that would be sent for a generation
+type PromptPlan struct {
+	Prompt string
+	Model  string
+	// Tokens is the estimated size of Prompt and Budget the most the model can take
+	Tokens int
+	Budget int
+	// Chunks is the number of summarization requests needed before the final
+	// prompt can be built; 0 when the commits fit in a single prompt
+	Chunks int
+}
+
+// PlanPrompt builds the prompt p would receive without calling the model.
+// For ranges over the budget, Prompt is the unsplit prompt and Chunks says how
+// many summarization requests would run first.
+func PlanPrompt(p Provider, req Request) (PromptPlan, error) {
+	model, budget := promptBudget(p)
+	req.Platforms = NormalizePlatforms(req.Platforms)
+	prompt, err := buildPrompt(req)
+	if err != nil {
+		return PromptPlan{}, err
+	}
+	plan := PromptPlan{Prompt: prompt, Model: model, Tokens: EstimateTokens(model, prompt), Budget: budget}
+	if plan.Tokens > budget {
+		plan.Chunks = len(chunkItems(model, commitLines(req.Commits), chunkBudget(model, budget)))
+	}
+	return plan, nil
+}
+
+// preparePrompt returns the prompt for the commits. When they do not fit in the
+// provider's context window, they are summarized chunk by chunk into an
+// intermediate changelog (map), and the posts are generated from the merged
+// summaries (reduce), summarizing again if the merge is still too large.
+func preparePrompt(ctx context.Context, c chatCompleter, req Request) (string, error) {
+	model, budget := promptBudget(c)
+	prompt, err := buildPrompt(req)
+	if err != nil {
+		return "", err
+	}
+	if EstimateTokens(model, prompt) <= budget {
+		return prompt, nil
+	}
+
+	items := commitLines(req.Commits)
+	empty, err := buildSummaryPrompt("", req)
+	if err != nil {
+		return "", err
+	}
+	overhead := EstimateTokens(model, empty)
+	kind := "Git commit messages"
+	for round := 0; round < maxReduceRounds; round++ {
+		chunks := chunkItems(model, items, chunkBudget(model, budget))
+
+		// Size each summary so that all of them together fit in the final prompt
+		words := max(50, min(400, (budget-overhead)*3/4/len(chunks)))
+
+		summaries := make([]string, 0, len(chunks))
+		for _, chunk := range chunks {
+			summary, err := summarizeChunk(ctx, c, kind, chunk, words)
+			if err != nil {
+				return "", err
+			}
+			summaries = append(summaries, summary)
+		}
+
+		prompt, err = buildSummaryPrompt(strings.Join(summaries, "\n"), req)
+		if err != nil {
+			return "", err
+		}
+		if EstimateTokens(model, prompt) <= budget || len(summaries) == 1 {
+			break
+		}
+		items = summaries
+		kind = "partial changelogs"
+	}
+	return prompt, nil
+}
+
+// summarizeChunk asks the model to condense one chunk into a bullet-point changelog
+func summarizeChunk(ctx context.Context, c chatCompleter, kind string, chunk []string, words int) (string, error) {
+	heading := "Commit Messages"
+	if kind != "Git commit messages" {
+		heading = "Partial Changelogs"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(summarizeInstructions, kind, words, heading))
+	for _, item := range chunk {
+		sb.WriteString(item)
+		sb.WriteString("\n")
+	}
+
+	out, err := c.complete(ctx, chatRequest{Messages: []chatMessage{{Role: "user", Content: sb.String()}}})
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize commits: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// chunkBudget is how many tokens of commit text fit in one summarization request
+func chunkBudget(model string, budget int) int {
+	return budget - EstimateTokens(model, summarizeInstructions) - 64
+}
+
+// chunkItems splits items into consecutive chunks that each fit within budget
+// tokens. Items larger than the budget on their own are truncated.
+func chunkItems(model string, items []string, budget int) [][]string {
+	var chunks [][]string
+	var current []string
+	used := 0
+	for _, item := range items {
+		tokens := EstimateTokens(model, item) + 1
+		if tokens > budget {
+			item = truncateRunes(item, int(float64(budget)*charsPerToken(model))-1)
+			tokens = budget
+		}
+		if used+tokens > budget && len(current) > 0 {
+			chunks = append(chunks, current)
+			current, used = nil, 0
+		}
+		current = append(current, item)
+		used += tokens
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// commitLines formats commits as the bullet lines used in prompts
+func commitLines(commits []git.Commit) []string {
+	lines := make([]string, 0, len(commits))
+	for _, c := range commits {
+		lines = append(lines, "- "+c.Message)
+	}
+	return lines
+}
+
+func truncateRunes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,268 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/kurtiz/commit-feed/internals/git"
+)
+
+// promptTemplate is the template rendered into the generation prompt
+const promptTemplate = "prompt.tmpl"
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// templateFuncs are the helper functions available to prompt templates
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	"label": PlatformLabel,
+}
+
+// Templates renders prompts from Go text/template files
+type Templates struct {
+	tmpl *template.Template
+	// Overrides lists the template files loaded in place of the defaults
+	Overrides []string
+	// fingerprint identifies the override contents; empty for the defaults
+	fingerprint string
+}
+
+// PromptData is the data prompt templates are rendered with
+type PromptData struct {
+	Commits []git.Commit
+	// Summarized is set when the commits did not fit in the prompt and
+	// Changelog summarizes them instead; Commits is empty then
+	Summarized     bool
+	Changelog      string
+	Stats          PromptStats
+	ProjectContext string
+	Platforms      []PlatformInfo
+	// Voice is the selected voice profile, or nil; Examples holds a few of its
+	// example posts for the target platforms
+	Voice    *Voice
+	Examples []VoiceExample
+	// Languages lists the names of all requested languages, if any
+	Languages []string
+}
+
+// PromptStats summarizes the commit range
+type PromptStats struct {
+	Commits int
+	// Authors lists each author once, in commit order
+	Authors      []string
+	Since, Until time.Time
+	// Types counts commits by conventional commit type (feat, fix, ...)
+	Types map[string]int
+}
+
+// PlatformInfo describes a target platform to templates
+type PlatformInfo struct {
+	Name  string
+	Label string
+	// Limit is the most characters a post may have, or 0 if there is no limit
+	Limit int
+	// PartLimit is the room for each part of a thread, leaving space for its "1/n" number
+	PartLimit int
+	// Thread is set when a thread is requested and the platform supports it
+	Thread bool
+	// Languages lists the names of the languages to write a post in, one post
+	// each; it is empty when no language was requested for the platform
+	Languages []string
+}
+
+var defaultTemplatesOnce = sync.OnceValue(func() *Templates {
+	t, err := LoadTemplates()
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded prompt templates: %v", err))
+	}
+	return t
+})
+
+// DefaultTemplates returns the templates embedded in CommitFeed
+func DefaultTemplates() *Templates {
+	return defaultTemplatesOnce()
+}
+
+// LoadTemplates parses the embedded default templates and any *.tmpl files in
+// dirs, which are given in lookup order: a file found in an earlier directory
+// replaces the file of the same name in later ones and in the defaults.
+// Directories that do not exist are skipped.
+func LoadTemplates(dirs ...string) (*Templates, error) {
+	tmpl, err := template.New(promptTemplate).Funcs(templateFuncs).ParseFS(defaultTemplates, "templates/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	for _, dir := range dirs {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range matches {
+			if _, ok := files[filepath.Base(path)]; !ok {
+				files[filepath.Base(path)] = path
+			}
+		}
+	}
+
+	t := &Templates{tmpl: tmpl}
+	h := sha256.New()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		data, err := os.ReadFile(files[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		if _, err := tmpl.New(name).Parse(string(data)); err != nil {
+			return nil, fmt.Errorf("invalid template %s: %v", files[name], err)
+		}
+		t.Overrides = append(t.Overrides, files[name])
+		fmt.Fprintf(h, "%s\x00%s\x00", name, data)
+	}
+	if len(names) > 0 {
+		t.fingerprint = hex.EncodeToString(h.Sum(nil))
+	}
+	return t, nil
+}
+
+// Fingerprint identifies the loaded template overrides, so that output
+// rendered from different templates can be told apart. It is empty when only
+// the defaults are used.
+func (t *Templates) Fingerprint() string {
+	if t == nil {
+		return ""
+	}
+	return t.fingerprint
+}
+
+// render executes the prompt template
+func (t *Templates) render(data PromptData) (string, error) {
+	var sb strings.Builder
+	if err := t.tmpl.ExecuteTemplate(&sb, promptTemplate, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// execute executes the named template, such as "offline post"
+func (t *Templates) execute(name string, data any) (string, error) {
+	var sb strings.Builder
+	if err := t.tmpl.ExecuteTemplate(&sb, name, data); err != nil {
+		return "", fmt.Errorf("failed to render %q template: %w", name, err)
+	}
+	return sb.String(), nil
+}
+
+// ExportTemplates writes the default templates into dir so they can be edited.
+// Existing files are left alone unless overwrite is set. It returns the paths
+// written and the paths skipped.
+func ExportTemplates(dir string, overwrite bool) (written, skipped []string, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create template dir: %w", err)
+	}
+	entries, err := fs.ReadDir(defaultTemplates, "templates")
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if _, err := os.Stat(path); err == nil && !overwrite {
+			skipped = append(skipped, path)
+			continue
+		}
+		data, err := defaultTemplates.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			return written, skipped, err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return written, skipped, fmt.Errorf("failed to write template: %w", err)
+		}
+		written = append(written, path)
+	}
+	return written, skipped, nil
+}
+
+// conventionalPrefix matches a conventional commit header such as "feat(api)!: ..."
+var conventionalPrefix = regexp.MustCompile(`^(\w+)(\([^)]*\))?!?:\s`)
+
+// conventionalType returns the conventional commit type of a message, or "" if it has none
+func conventionalType(message string) string {
+	if m := conventionalPrefix.FindStringSubmatch(message); m != nil {
+		return strings.ToLower(m[1])
+	}
+	return ""
+}
+
+// promptData collects the template data for a request
+func promptData(req Request) PromptData {
+	data := PromptData{
+		Commits:        req.Commits,
+		ProjectContext: req.ProjectContext,
+		Stats:          PromptStats{Commits: len(req.Commits), Types: make(map[string]int)},
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range req.Commits {
+		if c.Author != "" && !seen[c.Author] {
+			seen[c.Author] = true
+			data.Stats.Authors = append(data.Stats.Authors, c.Author)
+		}
+		if !c.Date.IsZero() {
+			if data.Stats.Since.IsZero() || c.Date.Before(data.Stats.Since) {
+				data.Stats.Since = c.Date
+			}
+			if c.Date.After(data.Stats.Until) {
+				data.Stats.Until = c.Date
+			}
+		}
+		if t := conventionalType(c.Message); t != "" {
+			data.Stats.Types[t]++
+		}
+	}
+
+	for _, t := range req.Targets() {
+		if t.Lang != "" {
+			name := LanguageName(t.Lang)
+			if !slices.Contains(data.Languages, name) {
+				data.Languages = append(data.Languages, name)
+			}
+		}
+		if n := len(data.Platforms); n > 0 && data.Platforms[n-1].Name == t.Platform {
+			data.Platforms[n-1].Languages = append(data.Platforms[n-1].Languages, LanguageName(t.Lang))
+			continue
+		}
+		info := PlatformInfo{Name: t.Platform, Label: PlatformLabel(t.Platform), Limit: CharLimit(t.Platform), Thread: req.threaded(t.Platform)}
+		if info.Limit > 0 {
+			// Leave room for numbering such as "10/12 "
+			info.PartLimit = info.Limit - 8
+		}
+		if t.Lang != "" {
+			info.Languages = []string{LanguageName(t.Lang)}
+		}
+		data.Platforms = append(data.Platforms, info)
+	}
+
+	data.Voice = req.Voice
+	data.Examples = voiceExamples(req.Voice, NormalizePlatforms(req.Platforms))
+	return data
+}
@@ -0,0 +1,96 @@
+package ai
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// defaultContextWindow is assumed for models missing from contextWindows
+	defaultContextWindow = 8192
+	// outputReserve is the part of the context window kept free for the model's answer
+	outputReserve = 2048
+)
+
+// contextWindows maps model name prefixes to their context window in tokens.
+// The longest matching prefix wins.
+var contextWindows = map[string]int{
+	"gpt-3.5":               16385,
+	"gpt-4":                 8192,
+	"gpt-4-turbo":           128000,
+	"gpt-4o":                128000,
+	"gpt-4.1":               1047576,
+	"gpt-5":                 400000,
+	"o1":                    200000,
+	"o3":                    200000,
+	"o4":                    200000,
+	"gemini-1.5-flash":      1048576,
+	"gemini-1.5-pro":        2097152,
+	"gemini-2":              1048576,
+	"deepseek-chat":         65536,
+	"deepseek-reasoner":     65536,
+	"grok-3":                131072,
+	"grok-4":                256000,
+	"openai/gpt-oss":        131072,
+	"meta-llama/llama-3":    131072,
+	"qwen/qwen2.5":          32768,
+	"mistralai/mistral-7b":  32768,
+	"google/gemma":          8192,
+	"deepseek-ai/deepseek-": 65536,
+}
+
+// ContextWindow returns the context window, in tokens, of a model
+func ContextWindow(model string) int {
+	model = strings.ToLower(model)
+	prefixes := make([]string, 0, len(contextWindows))
+	for prefix := range contextWindows {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(model, prefix) {
+			return contextWindows[prefix]
+		}
+	}
+	return defaultContextWindow
+}
+
+// EstimateTokens approximates how many tokens text takes for a model. It is a
+// character-based heuristic, deliberately on the high side, not a tokenizer.
+func EstimateTokens(model, text string) int {
+	if text == "" {
+		return 0
+	}
+	return int(math.Ceil(float64(utf8.RuneCountInString(text)) / charsPerToken(model)))
+}
+
+// charsPerToken is the average number of characters per token for a model family
+func charsPerToken(model string) float64 {
+	model = strings.ToLower(model)
+	switch {
+	case strings.HasPrefix(model, "gpt-4o"), strings.HasPrefix(model, "gpt-4.1"), strings.HasPrefix(model, "gpt-5"),
+		strings.HasPrefix(model, "o1"), strings.HasPrefix(model, "o3"), strings.HasPrefix(model, "o4"),
+		strings.HasPrefix(model, "openai/gpt-oss"), strings.HasPrefix(model, "gemini"):
+		return 4.0 // large vocabularies
+	default:
+		return 3.5 // llama, mistral, qwen, deepseek and older tokenizers
+	}
+}
+
+// promptBudget returns how many prompt tokens a provider can accept while
+// leaving room for the answer
+func promptBudget(c any) (model string, budget int) {
+	window := defaultContextWindow
+	if m, ok := c.(interface{ Model() string }); ok {
+		model = m.Model()
+		window = ContextWindow(model)
+	}
+	// Some providers (e.g. Ollama) run models with a smaller window than the model supports
+	if w, ok := c.(interface{ contextWindow() int }); ok {
+		window = w.contextWindow()
+	}
+	return model, window - min(outputReserve, window/4)
+}
@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Usage counts the tokens the calls to one model used
+type Usage struct {
+	Provider         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	// Requests is the number of model calls counted
+	Requests int
+	// Estimated is set when the provider did not report the usage of some of
+	// the calls and their tokens were estimated from the text instead
+	Estimated bool
+}
+
+// TotalTokens returns the prompt and completion tokens together
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// UsageMeter adds up, per provider and model, the usage of the model calls made with a
+// context from WithUsageMeter. It is safe for concurrent use.
+type UsageMeter struct {
+	mu     sync.Mutex
+	usage  []Usage
+	parent *UsageMeter
+}
+
+type usageMeterKey struct{}
+
+// WithUsageMeter returns a context whose model calls are counted by m, as well
+// as by any meter ctx already carries
+func WithUsageMeter(ctx context.Context, m *UsageMeter) context.Context {
+	m.parent, _ = ctx.Value(usageMeterKey{}).(*UsageMeter)
+	return context.WithValue(ctx, usageMeterKey{}, m)
+}
+
+// Usage returns the usage counted so far, one entry per model in the order the models were first used
+func (m *UsageMeter) Usage() []Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Usage(nil), m.usage...)
+}
+
+func (m *UsageMeter) add(u Usage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.usage {
+		if m.usage[i].Provider == u.Provider && m.usage[i].Model == u.Model {
+			m.usage[i].PromptTokens += u.PromptTokens
+			m.usage[i].CompletionTokens += u.CompletionTokens
+			m.usage[i].Requests += u.Requests
+			m.usage[i].Estimated = m.usage[i].Estimated || u.Estimated
+			return
+		}
+	}
+	m.usage = append(m.usage, u)
+}
+
+// recordUsage counts the tokens of one model call in the meters of ctx. When
+// the provider reported no usage, it is estimated from the prompt text and outputs.
+func recordUsage(ctx context.Context, provider, model string, prompt, completion int, promptText string, outputs ...string) {
+	m, _ := ctx.Value(usageMeterKey{}).(*UsageMeter)
+	if m == nil {
+		return
+	}
+
+	u := Usage{Provider: provider, Model: model, PromptTokens: prompt, CompletionTokens: completion, Requests: 1}
+	if prompt == 0 && completion == 0 {
+		u.Estimated = true
+		u.PromptTokens = EstimateTokens(model, promptText)
+		for _, out := range outputs {
+			u.CompletionTokens += EstimateTokens(model, out)
+		}
+	}
+	for ; m != nil; m = m.parent {
+		m.add(u)
+	}
+}
+
+// messagesText joins the content of chat messages, for estimating their tokens
+func messagesText(messages []chatMessage) string {
+	var sb strings.Builder
+	for _, m := range messages {
+		sb.WriteString(m.Content)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
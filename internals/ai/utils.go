@@ -3,84 +3,60 @@ package ai
 import (
 	"fmt"
 	"strings"
-
-	"github.com/kurtiz/commit-feed/internals/git"
 )
 
-// buildPrompt creates an AI prompt customized for the target platforms.
-func buildPrompt(commits []git.Commit, platforms []string, projectContext string) string {
-	var sb strings.Builder
-
-	sb.WriteString(`You are a skilled technical copywriter who creates engaging, platform-appropriate posts for developers and tech audiences.
+// PromptVersion identifies the prompt and output format. Bump it whenever
+// the default templates or the expected JSON shape change so cached posts are not reused.
+const PromptVersion = 4
 
-Your task is to generate short, high-quality social media posts based on the following Git commit messages.
-Each commit represents a meaningful code change, bug fix, or feature update.
+// buildPrompt creates an AI prompt customized for the target platforms.
+func buildPrompt(req Request) (string, error) {
+	return renderPrompt(req, promptData(req))
+}
 
---- Commit Messages ---
-`)
+// buildSummaryPrompt is buildPrompt for ranges too large to list commit by
+// commit: the posts are based on a changelog summarized from the commits
+func buildSummaryPrompt(summary string, req Request) (string, error) {
+	data := promptData(req)
+	data.Commits = nil
+	data.Summarized = true
+	data.Changelog = strings.TrimSpace(summary)
+	return renderPrompt(req, data)
+}
 
-	for _, c := range commits {
-		sb.WriteString(fmt.Sprintf("- %s\n", c.Message))
+// renderPrompt renders the prompt template and appends the instructions on the
+// JSON output format, which stay out of the templates so that edited templates
+// cannot break parsing of the response
+func renderPrompt(req Request, data PromptData) (string, error) {
+	templates := req.Templates
+	if templates == nil {
+		templates = DefaultTemplates()
 	}
-
-	if projectContext != "" {
-		sb.WriteString(fmt.Sprintf("\n--- Project Context ---\n%s\n", projectContext))
+	text, err := templates.render(data)
+	if err != nil {
+		return "", err
 	}
 
-	sb.WriteString("\n--- Platform Guidelines ---\n")
-
-	for _, platform := range platforms {
-		switch strings.ToLower(platform) {
-		case "linkedin":
-			sb.WriteString(`• LinkedIn: Write a friendly and professional summary (5-6 sentences). Explain what changed and why it matters to developers or users. add relevant hashtags.
-`)
-		case "twitter", "x":
-			sb.WriteString(`• Twitter/X: Write a short, catchy summary under 280 characters. Include emojis or hashtags if natural.
-`)
-		case "mastodon":
-			sb.WriteString(`• Mastodon: Write an open-source community-style update with clear tone and hashtags if relevant.
-`)
-		case "devto", "dev.to":
-			sb.WriteString(`• Dev.to: Write a short blog teaser — 2-3 sentences that introduce the update and invite readers to learn more.
-`)
-		case "reddit":
-			sb.WriteString(`• Reddit: Write a conversational summary that would fit in a /r/programming or /r/golang post, no emojis. add relevant flair if possible.
-`)
-		default:
-			sb.WriteString(fmt.Sprintf("• %s: Write a concise summary highlighting the main purpose and value of the change.\n", platform))
+	var sb strings.Builder
+	sb.WriteString(strings.TrimRight(text, "\n"))
+	sb.WriteString("\n\nRespond with ONLY a JSON object (no markdown fences, no commentary) in exactly this shape:\n")
+	sb.WriteString(`{"posts": {`)
+	for i, t := range req.Targets() {
+		if i > 0 {
+			sb.WriteString(", ")
 		}
-	}
-
-	sb.WriteString(`
-
-Format your response EXACTLY like this (one line per platform):
-LinkedIn: <LinkedIn post>
-Twitter: <Twitter post>
-Mastodon: <Mastodon post>
-... etc.
-
-Be creative but accurate. Focus on clarity, developer value, and readability.
-`)
-
-	return sb.String()
-}
-
-// parseResponse extracts LinkedIn and Twitter text from LLM response
-func parseResponse(text string) *GeneratedPosts {
-	posts := &GeneratedPosts{}
-	lines := strings.Split(text, "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(strings.ToLower(line), "linkedin:") {
-			posts.LinkedIn = strings.TrimSpace(strings.TrimPrefix(line, "LinkedIn:"))
-		} else if strings.HasPrefix(strings.ToLower(line), "twitter:") {
-			posts.Twitter = strings.TrimSpace(strings.TrimPrefix(line, "Twitter:"))
+		label := PlatformLabel(t.Platform)
+		in := ""
+		if t.Lang != "" {
+			in = " in " + LanguageName(t.Lang)
 		}
+		if req.threaded(t.Platform) {
+			sb.WriteString(fmt.Sprintf(`"%s": ["<%s part 1%s>", "<%s part 2%s>", ...]`, t.Key(), label, in, label, in))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf(`"%s": "<%s post%s>"`, t.Key(), label, in))
 	}
-	if posts.LinkedIn == "" {
-		posts.LinkedIn = text
-	}
-	if posts.Twitter == "" {
-		posts.Twitter = text
-	}
-	return posts
+	sb.WriteString("}}\n")
+
+	return sb.String(), nil
 }
@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// MaxVariants is the most alternatives that can be requested per platform
+const MaxVariants = 8
+
+// multiCompleter is implemented by providers whose API can return several
+// choices for a single request (OpenAI's "n", Gemini's candidate count)
+type multiCompleter interface {
+	chatCompleter
+	completeN(ctx context.Context, req chatRequest) ([]string, error)
+}
+
+// generateVariants produces n alternative posts per platform, in one request
+// when the provider supports it and with parallel requests otherwise. Variants
+// that stay invalid after repair are dropped as long as one of them succeeds.
+func generateVariants(ctx context.Context, c chatCompleter, chat chatRequest, req Request) (*GeneratedPosts, error) {
+	n := min(req.Variants, MaxVariants)
+	results := make([]*GeneratedPosts, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	if mc, ok := c.(multiCompleter); ok {
+		multi := chat
+		multi.N = n
+		outputs, err := mc.completeN(ctx, multi)
+		if err != nil {
+			return nil, err
+		}
+		if len(outputs) == 0 {
+			return nil, fmt.Errorf("no response content returned")
+		}
+		results, errs = results[:len(outputs)], errs[:len(outputs)]
+		for i, out := range outputs {
+			// Each choice is validated, and repaired if needed, on its own
+			first := func(context.Context, chatRequest) (string, error) { return out, nil }
+			wg.Go(func() {
+				results[i], errs[i] = structuredLoop(ctx, c, first, chat, req)
+			})
+		}
+	} else {
+		for i := range n {
+			wg.Go(func() {
+				results[i], errs[i] = structuredLoop(ctx, c, c.complete, chat, req)
+			})
+		}
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return mergeVariants(results, errs, req.Targets())
+}
+
+// mergeVariants combines the successful generations into one set of posts
+// whose Variants list every distinct text per target
+func mergeVariants(results []*GeneratedPosts, errs []error, targets []Target) (*GeneratedPosts, error) {
+	merged := &GeneratedPosts{Posts: make(map[string]Post, len(targets))}
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		for _, t := range targets {
+			post := merged.Posts[t.Key()]
+			post.Platform, post.Lang = t.Platform, t.Lang
+			text := r.Posts[t.Key()].Text
+			if !slices.Contains(post.Variants, text) {
+				post.Variants = append(post.Variants, text)
+			}
+			post.Text = post.Variants[0]
+			merged.Posts[t.Key()] = post
+		}
+	}
+	if len(merged.Posts) == 0 {
+		for _, err := range errs {
+			if err != nil {
+				return nil, err
+			}
+		}
+		return nil, fmt.Errorf("no variants were generated")
+	}
+	return merged, nil
+}
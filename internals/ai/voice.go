@@ -0,0 +1,96 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// maxVoiceExamples is how many example posts per platform go into a prompt
+const maxVoiceExamples = 3
+
+// Emoji policies of a voice
+const (
+	EmojiNone    = "none"
+	EmojiSparing = "sparing"
+	EmojiFree    = "free"
+)
+
+// Voice describes how posts should sound, e.g. like a team's earlier posts
+type Voice struct {
+	Name string
+	// Tone describes the voice in a few words or sentences
+	Tone string
+	// BannedPhrases must not appear in any post
+	BannedPhrases []string
+	// Emoji is the emoji policy: EmojiNone, EmojiSparing, EmojiFree or "" to leave it to the platform guidelines
+	Emoji    string
+	Examples []VoiceExample
+}
+
+// VoiceExample is an earlier post written in the voice
+type VoiceExample struct {
+	// Platform is the platform the post was written for, or "" if unknown
+	Platform string
+	Text     string
+}
+
+// voiceExamples picks up to maxVoiceExamples examples for each target platform,
+// preferring examples from that platform over ones of unknown origin
+func voiceExamples(v *Voice, platforms []string) []VoiceExample {
+	if v == nil {
+		return nil
+	}
+	var picked []VoiceExample
+	used := make(map[int]bool)
+	for _, p := range platforms {
+		n := 0
+		for _, generic := range []bool{false, true} {
+			for i, ex := range v.Examples {
+				if n == maxVoiceExamples {
+					break
+				}
+				platform := NormalizePlatform(ex.Platform)
+				if used[i] || (generic && platform != "") || (!generic && platform != p) {
+					continue
+				}
+				used[i] = true
+				picked = append(picked, VoiceExample{Platform: platform, Text: strings.TrimSpace(ex.Text)})
+				n++
+			}
+		}
+	}
+	return picked
+}
+
+// voiceProblems lists how a post breaks the voice's rules
+func voiceProblems(v *Voice, key, text string) []string {
+	if v == nil {
+		return nil
+	}
+	var problems []string
+	lower := strings.ToLower(text)
+	for _, phrase := range v.BannedPhrases {
+		if phrase = strings.TrimSpace(phrase); phrase != "" && strings.Contains(lower, strings.ToLower(phrase)) {
+			problems = append(problems, fmt.Sprintf("posts.%s uses the banned phrase %q", key, phrase))
+		}
+	}
+	if v.Emoji == EmojiNone && containsEmoji(text) {
+		problems = append(problems, fmt.Sprintf("posts.%s must not contain emoji", key))
+	}
+	return problems
+}
+
+// containsEmoji reports whether text contains an emoji
+func containsEmoji(text string) bool {
+	state := -1
+	var cluster string
+	for len(text) > 0 {
+		cluster, text, _, state = uniseg.FirstGraphemeClusterInString(text, state)
+		if isEmoji(cluster) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Dir returns the cache directory (~/.commit-feed/cache)
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot get home dir: %w", err)
+	}
+	return filepath.Join(home, ".commit-feed", "cache"), nil
+}
+
+// Key returns a content address for v: the SHA-256 of its JSON encoding
+func Key(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cache key: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Get loads the entry stored under key into v. It reports false when there is no entry.
+func Get(key string, v any) (bool, error) {
+	path, err := entryPath(key)
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read cache entry: %v", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		// A corrupt entry is treated as a miss and will be overwritten
+		return false, nil
+	}
+	return true, nil
+}
+
+// Put stores v under key
+func Put(key string, v any) error {
+	path, err := entryPath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %v", err)
+	}
+
+	// Write to a temp file first so readers never see a partial entry
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+	return nil
+}
+
+// PruneResult summarizes what Prune removed
+type PruneResult struct {
+	Removed   int
+	Freed     int64
+	Remaining int
+	Size      int64
+}
+
+// Prune deletes entries older than maxAge, then the oldest remaining entries
+// until the cache is no larger than maxSize bytes. Zero disables a limit.
+func Prune(maxSize int64, maxAge time.Duration) (PruneResult, error) {
+	var result PruneResult
+
+	dir, err := Dir()
+	if err != nil {
+		return result, err
+	}
+	dirEntries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to read cache directory: %v", err)
+	}
+
+	type entry struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var entries []entry
+	for _, de := range dirEntries {
+		if de.IsDir() || filepath.Ext(de.Name()) != ".json" {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry{filepath.Join(dir, de.Name()), info.Size(), info.ModTime()})
+	}
+
+	// Oldest first, so size-based eviction drops the least recently written entries
+	sort.Slice(entries, func(i, j int) bool { return entries[i].modTime.Before(entries[j].modTime) })
+
+	var total int64
+	for _, e := range entries {
+		total += e.size
+	}
+
+	for _, e := range entries {
+		expired := maxAge > 0 && time.Since(e.modTime) > maxAge
+		oversized := maxSize > 0 && total > maxSize
+		if !expired && !oversized {
+			result.Remaining++
+			continue
+		}
+		if err := os.Remove(e.path); err != nil {
+			return result, fmt.Errorf("failed to remove cache entry: %v", err)
+		}
+		result.Removed++
+		result.Freed += e.size
+		total -= e.size
+	}
+	result.Size = total
+
+	return result, nil
+}
+
+func entryPath(key string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, key+".json"), nil
+}
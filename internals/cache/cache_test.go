@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	type input struct {
+		Provider string   `json:"provider"`
+		Commits  []string `json:"commits"`
+	}
+	a, err := Key(input{Provider: "openai", Commits: []string{"a1b2c3d"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := Key(input{Provider: "openai", Commits: []string{"a1b2c3d"}}); again != a {
+		t.Errorf("the same input gave %s and %s", a, again)
+	}
+	if len(a) != 64 || strings.Trim(a, "0123456789abcdef") != "" {
+		t.Errorf("key %q is not a hex SHA-256", a)
+	}
+	for _, other := range []input{{Provider: "gemini", Commits: []string{"a1b2c3d"}}, {Provider: "openai", Commits: []string{"e4f5a6b"}}, {Provider: "openai"}} {
+		if k, _ := Key(other); k == a {
+			t.Errorf("%+v has the same key as the original input", other)
+		}
+	}
+	if _, err := Key(func() {}); err == nil {
+		t.Error("encoded a function")
+	}
+}
+
+func TestGetPut(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	type posts struct{ Twitter string }
+
+	var got posts
+	if ok, err := Get("missing", &got); ok || err != nil {
+		t.Fatalf("Get of a missing entry = %v, %v", ok, err)
+	}
+	if err := Put("k", posts{Twitter: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := Get("k", &got); !ok || err != nil || got.Twitter != "Hello" {
+		t.Fatalf("Get = %v, %v, %+v", ok, err, got)
+	}
+
+	// A corrupt entry is a miss
+	path, _ := entryPath("k")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := Get("k", &got); ok || err != nil {
+		t.Errorf("Get of a corrupt entry = %v, %v", ok, err)
+	}
+}
+
+// writeEntries creates 100-byte cache entries, each as old as its age
+func writeEntries(t *testing.T, ages map[string]time.Duration) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	dir, _ := Dir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, age := range ages {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(strings.Repeat("x", 100)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(-age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestPrune(t *testing.T) {
+	day := 24 * time.Hour
+	ages := map[string]time.Duration{
+		"a.json": 10 * day,
+		"b.json": 8 * day,
+		"c.json": 3 * day,
+		"d.json": 2 * day,
+		"e.json": day,
+	}
+	tests := []struct {
+		name    string
+		maxSize int64
+		maxAge  time.Duration
+		kept    []string
+	}{
+		{"no limits", 0, 0, []string{"a.json", "b.json", "c.json", "d.json", "e.json"}},
+		{"by age", 0, 7 * day, []string{"c.json", "d.json", "e.json"}},
+		{"by size, oldest first", 250, 0, []string{"d.json", "e.json"}},
+		{"by age then size", 150, 7 * day, []string{"e.json"}},
+		{"size already within the limit after age", 300, 7 * day, []string{"c.json", "d.json", "e.json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeEntries(t, ages)
+			// Other files in the cache directory are left alone
+			if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := Prune(tt.maxSize, tt.maxAge)
+			if err != nil {
+				t.Fatal(err)
+			}
+			removed := len(ages) - len(tt.kept)
+			want := PruneResult{Removed: removed, Freed: int64(removed) * 100, Remaining: len(tt.kept), Size: int64(len(tt.kept)) * 100}
+			if result != want {
+				t.Errorf("Prune = %+v, want %+v", result, want)
+			}
+
+			left, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+			var names []string
+			for _, path := range left {
+				names = append(names, filepath.Base(path))
+			}
+			if strings.Join(names, " ") != strings.Join(tt.kept, " ") {
+				t.Errorf("kept %q, want %q", names, tt.kept)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+				t.Errorf("removed a file that is not a cache entry: %v", err)
+			}
+		})
+	}
+}
+
+func TestPruneWithoutCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if result, err := Prune(1, time.Hour); err != nil || result != (PruneResult{}) {
+		t.Errorf("Prune = %+v, %v", result, err)
+	}
+}
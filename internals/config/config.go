@@ -12,6 +12,108 @@ type Config struct {
 	Provider         string   `json:"provider"`
 	APIKey           string   `json:"api_key"`
 	DefaultPlatforms []string `json:"default_platforms"`
+	// Providers holds optional per-provider settings keyed by provider name
+	Providers map[string]ProviderConfig `json:"providers,omitempty"`
+	// Fallbacks are tried in order when the primary provider hits a retryable or quota error
+	Fallbacks []ProviderEntry `json:"fallbacks,omitempty"`
+	// Voices holds named voice profiles; Voice names the one used when --voice is not given
+	Voices map[string]VoiceProfile `json:"voices,omitempty"`
+	Voice  string                  `json:"voice,omitempty"`
+	// Languages maps a platform to the languages its posts are written in when
+	// --lang is not given, one post per language (e.g. "linkedin": ["en", "fr"])
+	Languages map[string][]string `json:"languages,omitempty"`
+	// Prices overrides the built-in model prices, keyed by model name prefix,
+	// "<provider>/<model>" prefix or provider name
+	Prices map[string]Price `json:"prices,omitempty"`
+	// MonthlyBudget, when above 0, is the most to spend on AI providers per
+	// calendar month, in US dollars; generation stops once it is reached
+	MonthlyBudget float64 `json:"monthly_budget,omitempty"`
+	// Plugins maps provider names to plugin executables that are not on PATH
+	// under the name commitfeed-provider-<name>
+	Plugins map[string]string `json:"plugins,omitempty"`
+}
+
+// Price is what a model charges, in US dollars per million tokens
+type Price struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// ProviderConfig holds settings for a single AI provider
+type ProviderConfig struct {
+	// APIKey overrides the top-level api_key for this provider
+	APIKey string `json:"api_key,omitempty"`
+	// BaseURL overrides the provider endpoint (e.g. the Ollama host)
+	BaseURL string `json:"base_url,omitempty"`
+	Model   string `json:"model,omitempty"`
+	// Headers are extra HTTP headers sent with every request
+	Headers map[string]string `json:"headers,omitempty"`
+	// AuthScheme selects how the API key is sent: "bearer" (default), "api-key" or "none"
+	AuthScheme string `json:"auth_scheme,omitempty"`
+	// ResponseFormat selects how an openai-compatible server is asked for JSON:
+	// "json_schema" (default), "json_object" or "none"
+	ResponseFormat string `json:"response_format,omitempty"`
+
+	// Sampling parameters; unset values use the provider defaults
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"top_p,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+}
+
+// ProviderEntry names a provider together with its settings
+type ProviderEntry struct {
+	Provider string `json:"provider"`
+	ProviderConfig
+}
+
+// ProviderChain returns the primary provider followed by the fallbacks, in order.
+// Settings left empty in a fallback entry are taken from the providers map.
+func (c *Config) ProviderChain() []ProviderEntry {
+	primary := ProviderEntry{Provider: c.Provider, ProviderConfig: c.Providers[c.Provider]}
+	if primary.APIKey == "" {
+		primary.APIKey = c.APIKey
+	}
+
+	chain := []ProviderEntry{primary}
+	for _, fb := range c.Fallbacks {
+		chain = append(chain, ProviderEntry{
+			Provider:       fb.Provider,
+			ProviderConfig: mergeProviderConfig(c.Providers[fb.Provider], fb.ProviderConfig),
+		})
+	}
+	return chain
+}
+
+// mergeProviderConfig overlays the non-empty fields of override onto base
+func mergeProviderConfig(base, override ProviderConfig) ProviderConfig {
+	if override.APIKey != "" {
+		base.APIKey = override.APIKey
+	}
+	if override.BaseURL != "" {
+		base.BaseURL = override.BaseURL
+	}
+	if override.Model != "" {
+		base.Model = override.Model
+	}
+	if override.Headers != nil {
+		base.Headers = override.Headers
+	}
+	if override.AuthScheme != "" {
+		base.AuthScheme = override.AuthScheme
+	}
+	if override.ResponseFormat != "" {
+		base.ResponseFormat = override.ResponseFormat
+	}
+	if override.Temperature != nil {
+		base.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		base.TopP = override.TopP
+	}
+	if override.MaxTokens != 0 {
+		base.MaxTokens = override.MaxTokens
+	}
+	return base
 }
 
 // Path returns the full config file path (~/.commit-feed/config.json)
@@ -23,6 +125,18 @@ func Path() (string, error) {
 	return filepath.Join(home, ".commit-feed", "config.json"), nil
 }
 
+// TemplatesDir returns the directory of the user's prompt templates (~/.commit-feed/templates)
+func TemplatesDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot get home dir: %w", err)
+	}
+	return filepath.Join(home, ".commit-feed", "templates"), nil
+}
+
+// RepoTemplatesDir is where a repository keeps its own prompt templates, relative to its root
+const RepoTemplatesDir = ".commitfeed/templates"
+
 // defaultConfig returns a basic default setup (used if user skips setup)
 func defaultConfig() *Config {
 	return &Config{
@@ -88,6 +202,32 @@ func Load() (*Config, error) {
 	return &cfg, nil
 }
 
+// Update applies change to the config file as stored, without environment
+// overrides, and saves the result. Unlike Load, it fails on an invalid file
+// rather than replacing it with the defaults.
+func Update(change func(cfg *Config) error) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	cfg := defaultConfig()
+	data, err := os.ReadFile(path)
+	if err == nil {
+		cfg = &Config{}
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return fmt.Errorf("invalid config file %s: %v", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config: %v", err)
+	}
+
+	if err := change(cfg); err != nil {
+		return err
+	}
+	return Save(cfg)
+}
+
 // EnsureExists loads config if present, otherwise runs the setup wizard
 func EnsureExists() (*Config, error) {
 	path, err := Path()
@@ -96,15 +236,31 @@ func EnsureExists() (*Config, error) {
 	}
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		fmt.Println("🧩 No config found — launching first-time setup...")
-		cfg, err := RunSetupWizard()
-		if err != nil {
-			fmt.Println("⚠️ Setup canceled or failed — using default configuration.")
-			cfg = defaultConfig()
-			if saveErr := Save(cfg); saveErr != nil {
-				return nil, fmt.Errorf("failed to save default config: %v", saveErr)
-			}
-		}
-		return cfg, nil
+		return Setup()
 	}
 	return Load()
 }
+
+// Setup runs the setup wizard. When it is canceled or fails, an existing
+// config is left as it was, and the default configuration is saved if there is none.
+func Setup() (*Config, error) {
+	cfg, err := RunSetupWizard()
+	if err == nil {
+		return cfg, nil
+	}
+
+	path, pathErr := Path()
+	if pathErr != nil {
+		return nil, pathErr
+	}
+	if _, statErr := os.Stat(path); statErr == nil {
+		fmt.Println("⚠️ Setup canceled or failed — keeping your existing configuration.")
+		return Load()
+	}
+	fmt.Println("⚠️ Setup canceled or failed — using default configuration.")
+	cfg = defaultConfig()
+	if saveErr := Save(cfg); saveErr != nil {
+		return nil, fmt.Errorf("failed to save default config: %v", saveErr)
+	}
+	return cfg, nil
+}
@@ -1,13 +1,15 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kurtiz/commit-feed/internals/ai"
 )
 
 var (
@@ -17,12 +19,14 @@ var (
 		Padding(1, 0)
 )
 
-// RunSetupWizard launches an interactive TUI to create the config file.
+// RunSetupWizard launches an interactive TUI to create the config file, or to
+// change the provider of an existing one. Settings the wizard does not ask
+// about, such as fallbacks, voices and prices, are kept.
 func RunSetupWizard() (*Config, error) {
 	fmt.Println(titleStyle.Render("🚀 Welcome to CommitFeed!"))
 	fmt.Println("Let's set up your AI provider to generate social posts from your git commits.")
 
-	providerOptions := []string{"huggingface (free default)", "gemini", "openai", "deepseek"}
+	providerOptions := []string{"huggingface (free default)", "gemini", "openai", "deepseek", "grok", "ollama (local, offline)"}
 	var providerChoice string
 	var apiKey string
 
@@ -53,10 +57,14 @@ func RunSetupWizard() (*Config, error) {
 		provider = "openai"
 	case providerChoice == "deepseek":
 		provider = "deepseek"
+	case providerChoice == "grok":
+		provider = "grok"
+	case strings.HasPrefix(providerChoice, "ollama"):
+		provider = "ollama"
 	}
 
 	// If non-default, show info on where to get the API key
-	if provider != "huggingface" && apiKey == "" {
+	if provider != "huggingface" && provider != "ollama" && apiKey == "" {
 		fmt.Printf("\n⚠️  You chose %s but didn’t provide an API key.\n", provider)
 		switch provider {
 		case "gemini":
@@ -65,17 +73,42 @@ func RunSetupWizard() (*Config, error) {
 			fmt.Println("👉 Get your key at: https://platform.openai.com/account/api-keys")
 		case "deepseek":
 			fmt.Println("👉 Get your key at: https://platform.deepseek.com/")
+		case "grok":
+			fmt.Println("👉 Get your key at: https://console.x.ai/")
 		}
 		fmt.Println("You can edit it later at ~/.commit-feed/config.json")
 	}
 
-	cfg := &Config{
-		Provider:         provider,
-		APIKey:           apiKey,
-		DefaultPlatforms: []string{"linkedin", "twitter"},
+	var ollamaCfg ProviderConfig
+	if provider == "ollama" {
+		var err error
+		if ollamaCfg, err = setupOllama(); err != nil {
+			return nil, err
+		}
 	}
 
-	if err := saveConfig(cfg); err != nil {
+	var cfg *Config
+	err := Update(func(c *Config) error {
+		// A blank key keeps the one already set for the same provider
+		if apiKey != "" || c.Provider != provider {
+			c.APIKey = apiKey
+		}
+		c.Provider = provider
+		if len(c.DefaultPlatforms) == 0 {
+			c.DefaultPlatforms = []string{"linkedin", "twitter"}
+		}
+		if provider == "ollama" {
+			if c.Providers == nil {
+				c.Providers = make(map[string]ProviderConfig)
+			}
+			settings := c.Providers["ollama"]
+			settings.BaseURL, settings.Model = ollamaCfg.BaseURL, ollamaCfg.Model
+			c.Providers["ollama"] = settings
+		}
+		cfg = c
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -85,13 +118,45 @@ func RunSetupWizard() (*Config, error) {
 	return cfg, nil
 }
 
-func saveConfig(cfg *Config) error {
-	path := configPath()
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return err
+// setupOllama asks for the Ollama host and lets the user pick one of the installed models
+func setupOllama() (ProviderConfig, error) {
+	host := ai.OllamaHost("")
+	if err := huh.NewInput().
+		Title("Ollama host").
+		Prompt("> ").
+		Value(&host).
+		Run(); err != nil {
+		return ProviderConfig{}, fmt.Errorf("setup cancelled: %v", err)
+	}
+	host = ai.OllamaHost(host)
+
+	model := ai.DefaultOllamaModel
+	models, err := ai.ListOllamaModels(host)
+	switch {
+	case err != nil:
+		fmt.Printf("\n⚠️  Could not list Ollama models: %v\n", err)
+	case len(models) == 0:
+		fmt.Printf("\n⚠️  No models installed in Ollama. Install one with `ollama pull %s`.\n", ai.DefaultOllamaModel)
+	default:
+		model = models[0]
+		if err := huh.NewSelect[string]().
+			Title("Choose an installed Ollama model").
+			Options(huh.NewOptions(models...)...).
+			Value(&model).
+			Run(); err != nil {
+			return ProviderConfig{}, fmt.Errorf("setup cancelled: %v", err)
+		}
+		return ProviderConfig{BaseURL: host, Model: model}, nil
+	}
+
+	if err := huh.NewInput().
+		Title("Ollama model name").
+		Prompt("> ").
+		Value(&model).
+		Run(); err != nil {
+		return ProviderConfig{}, fmt.Errorf("setup cancelled: %v", err)
 	}
-	data, _ := json.MarshalIndent(cfg, "", "  ")
-	return os.WriteFile(path, data, 0o644)
+	return ProviderConfig{BaseURL: host, Model: model}, nil
 }
 
 func configPath() string {
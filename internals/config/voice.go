@@ -0,0 +1,131 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// VoiceProfile describes how a team's posts should sound
+type VoiceProfile struct {
+	// Tone describes the voice in a few words or sentences
+	Tone string `json:"tone,omitempty"`
+	// BannedPhrases must never appear in a post
+	BannedPhrases []string `json:"banned_phrases,omitempty"`
+	// Emoji is the emoji policy: "none", "sparing" or "free"
+	Emoji    string         `json:"emoji,omitempty"`
+	Examples []VoiceExample `json:"examples,omitempty"`
+}
+
+// VoiceExample is an earlier post written in the voice
+type VoiceExample struct {
+	Platform string `json:"platform,omitempty"`
+	Text     string `json:"text"`
+}
+
+// separatorLine and blankLines split posts in a text export
+var (
+	separatorLine = regexp.MustCompile(`(?m)^\s*-{3,}\s*$`)
+	blankLines    = regexp.MustCompile(`\n\s*\n`)
+)
+
+// ParseVoiceExamples reads example posts from an export of earlier posts.
+// JSON exports may be a list of strings, a list of objects with the post in
+// "text", "full_text", "content" or "commentary" (X's tweets.js is accepted
+// as is). Text exports hold one post per block, separated by "---" lines or,
+// if there are none, by blank lines. platform is used for posts that do not
+// name their own.
+func ParseVoiceExamples(data []byte, platform string) ([]VoiceExample, error) {
+	trimmed := bytes.TrimSpace(data)
+	// X archives wrap the JSON list in "window.YTD.tweets.part0 = [...]"
+	if bytes.HasPrefix(trimmed, []byte("window.")) {
+		if i := bytes.IndexByte(trimmed, '['); i >= 0 {
+			trimmed = trimmed[i:]
+			if platform == "" {
+				platform = "twitter"
+			}
+		}
+	}
+
+	var texts []VoiceExample
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		var items []json.RawMessage
+		if trimmed[0] == '{' {
+			items = []json.RawMessage{trimmed}
+		} else if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("invalid JSON export: %v", err)
+		}
+		for _, item := range items {
+			ex, err := parseExample(item)
+			if err != nil {
+				return nil, err
+			}
+			texts = append(texts, ex)
+		}
+	} else {
+		blocks := separatorLine.Split(string(trimmed), -1)
+		if len(blocks) == 1 {
+			blocks = blankLines.Split(string(trimmed), -1)
+		}
+		for _, block := range blocks {
+			texts = append(texts, VoiceExample{Text: block})
+		}
+	}
+
+	var examples []VoiceExample
+	seen := make(map[string]bool)
+	for _, ex := range texts {
+		ex.Text = strings.TrimSpace(ex.Text)
+		// Reposts are someone else's voice
+		if ex.Text == "" || strings.HasPrefix(ex.Text, "RT @") || seen[ex.Text] {
+			continue
+		}
+		seen[ex.Text] = true
+		if ex.Platform == "" {
+			ex.Platform = platform
+		}
+		examples = append(examples, ex)
+	}
+	return examples, nil
+}
+
+// parseExample reads one post from a JSON export entry
+func parseExample(item json.RawMessage) (VoiceExample, error) {
+	var text string
+	if err := json.Unmarshal(item, &text); err == nil {
+		return VoiceExample{Text: text}, nil
+	}
+
+	var obj struct {
+		Platform   string          `json:"platform"`
+		Text       string          `json:"text"`
+		FullText   string          `json:"full_text"`
+		Content    string          `json:"content"`
+		Commentary string          `json:"commentary"`
+		Tweet      json.RawMessage `json:"tweet"`
+	}
+	if err := json.Unmarshal(item, &obj); err != nil {
+		return VoiceExample{}, fmt.Errorf("unsupported entry in JSON export: %s", truncate(string(item), 60))
+	}
+	if len(obj.Tweet) > 0 {
+		ex, err := parseExample(obj.Tweet)
+		ex.Platform = "twitter"
+		return ex, err
+	}
+	for _, t := range []string{obj.Text, obj.FullText, obj.Content, obj.Commentary} {
+		if t != "" {
+			return VoiceExample{Platform: obj.Platform, Text: t}, nil
+		}
+	}
+	return VoiceExample{}, nil
+}
+
+// truncate shortens s to n bytes for error messages
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -33,6 +33,15 @@ func IsGitRepo() bool {
 	return strings.TrimSpace(string(output)) == "true"
 }
 
+// RootDir returns the top-level directory of the current Git repository
+func RootDir() (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find repository root: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 // Fetch commits based on range or limit
 func GetCommits(rangeArg string, limit int) ([]Commit, error) {
 	if !IsGitInstalled() {
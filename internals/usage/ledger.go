@@ -0,0 +1,163 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Entry is the usage of one model in one generation, as kept in the ledger
+type Entry struct {
+	Time             time.Time `json:"time"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	Requests         int       `json:"requests"`
+	// Cost is in US dollars, at the prices known when the entry was recorded
+	Cost float64 `json:"cost"`
+	// Unpriced is set when no price was known for the model, leaving Cost at 0
+	Unpriced bool `json:"unpriced,omitempty"`
+	// Estimated is set when the provider did not report all token counts
+	Estimated bool `json:"estimated,omitempty"`
+}
+
+// Path returns the ledger file path (~/.commit-feed/usage.jsonl)
+func Path() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot get home dir: %w", err)
+	}
+	return filepath.Join(home, ".commit-feed", "usage.jsonl"), nil
+}
+
+// Record appends entries to the ledger
+func Record(entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create usage directory: %v", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open usage ledger: %v", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to write usage ledger: %v", err)
+		}
+	}
+	return f.Close()
+}
+
+// Load reads every ledger entry, oldest first. Lines that cannot be parsed are skipped.
+func Load() ([]Entry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %v", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %v", err)
+	}
+	return entries, nil
+}
+
+// Total sums ledger entries that share a key, such as a month or a model
+type Total struct {
+	Key              string
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64
+	// Unpriced and Estimated are set when any of the entries is
+	Unpriced  bool
+	Estimated bool
+}
+
+// ByMonth keys entries by the month they were recorded in, e.g. "2025-06"
+func ByMonth(e Entry) string {
+	return e.Time.Local().Format("2006-01")
+}
+
+// ByModel keys entries by provider and model, e.g. "openai/gpt-4o-mini"
+func ByModel(e Entry) string {
+	return e.Provider + "/" + e.Model
+}
+
+// Totals groups entries by key, in the order the keys first appear
+func Totals(entries []Entry, key func(Entry) string) []Total {
+	var totals []Total
+	index := make(map[string]int)
+	for _, e := range entries {
+		k := key(e)
+		i, ok := index[k]
+		if !ok {
+			i = len(totals)
+			index[k] = i
+			totals = append(totals, Total{Key: k})
+		}
+		t := &totals[i]
+		t.Requests += e.Requests
+		t.PromptTokens += e.PromptTokens
+		t.CompletionTokens += e.CompletionTokens
+		t.Cost += e.Cost
+		t.Unpriced = t.Unpriced || e.Unpriced
+		t.Estimated = t.Estimated || e.Estimated
+	}
+	return totals
+}
+
+// MonthCost returns what the entries recorded in the same month as t cost
+func MonthCost(entries []Entry, t time.Time) float64 {
+	month := t.Local().Format("2006-01")
+	var cost float64
+	for _, e := range entries {
+		if ByMonth(e) == month {
+			cost += e.Cost
+		}
+	}
+	return cost
+}
+
+// UnpricedModels lists the "<provider>/<model>" of the entries recorded
+// without a price, once each, in the order they first appear
+func UnpricedModels(entries []Entry) []string {
+	var models []string
+	for _, t := range Totals(entries, ByModel) {
+		if t.Unpriced {
+			models = append(models, t.Key)
+		}
+	}
+	return models
+}
@@ -0,0 +1,90 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLedgerRecordAndLoad(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if entries, err := Load(); err != nil || entries != nil {
+		t.Fatalf("Load without a ledger = %v, %v", entries, err)
+	}
+
+	march := time.Date(2025, 3, 31, 12, 0, 0, 0, time.Local)
+	first := Entry{Time: march, Provider: "openai", Model: "gpt-4o-mini", PromptTokens: 1000, CompletionTokens: 100, Requests: 1, Cost: 0.5}
+	second := Entry{Time: march.Add(24 * time.Hour), Provider: "ollama", Model: "llama3.2", PromptTokens: 800, Requests: 2}
+	if err := Record(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := Record(second); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Cost != 0.5 || entries[1].Model != "llama3.2" || !entries[1].Time.Equal(second.Time) {
+		t.Errorf("Load = %+v", entries)
+	}
+}
+
+func TestLedgerSkipsMalformedLines(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".commit-feed", "usage.jsonl")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ledger := `{"time": "2025-03-01T10:00:00Z", "provider": "openai", "model": "gpt-4o", "cost": 1.25}
+not json at all
+{"time": "2025-03-02T10:00:00Z", "provider": "openai", "model": "gpt-4o", "cost": "free"}
+
+{"time": "2025-03-03T10:00:00Z", "provider": "gemini", "model": "gemini-2.0-flash", "cost": 0.5}
+{"time": "2025-03-04T10:00:00Z", "provider": "gemini", "model": "gemini-2.0-fl`
+	if err := os.WriteFile(path, []byte(ledger), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Cost != 1.25 || entries[1].Provider != "gemini" {
+		t.Errorf("Load = %+v, want the two valid lines", entries)
+	}
+}
+
+func TestMonthlyTotals(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2025, month, d, 12, 0, 0, 0, time.Local) }
+	entries := []Entry{
+		{Time: day(2, 28), Provider: "openai", Model: "gpt-4o", PromptTokens: 100, Requests: 1, Cost: 1},
+		{Time: day(3, 1), Provider: "openai", Model: "gpt-4o", PromptTokens: 200, CompletionTokens: 20, Requests: 1, Cost: 2},
+		{Time: day(3, 15), Provider: "plugin", Model: "x", PromptTokens: 50, Requests: 3, Unpriced: true},
+		{Time: day(3, 31), Provider: "openai", Model: "gpt-4o", Requests: 2, Cost: 0.25, Estimated: true},
+		{Time: day(4, 1), Provider: "openai", Model: "gpt-4o", Requests: 1, Cost: 4},
+	}
+
+	want := []Total{
+		{Key: "2025-02", Requests: 1, PromptTokens: 100, Cost: 1},
+		{Key: "2025-03", Requests: 6, PromptTokens: 250, CompletionTokens: 20, Cost: 2.25, Unpriced: true, Estimated: true},
+		{Key: "2025-04", Requests: 1, Cost: 4},
+	}
+	if got := Totals(entries, ByMonth); !reflect.DeepEqual(got, want) {
+		t.Errorf("Totals by month = %+v, want %+v", got, want)
+	}
+	if got := MonthCost(entries, day(3, 10)); got != 2.25 {
+		t.Errorf("MonthCost(March) = %v, want 2.25", got)
+	}
+	if got := MonthCost(entries, day(5, 1)); got != 0 {
+		t.Errorf("MonthCost(May) = %v, want 0", got)
+	}
+	if got := UnpricedModels(entries); !reflect.DeepEqual(got, []string{"plugin/x"}) {
+		t.Errorf("UnpricedModels = %q", got)
+	}
+}
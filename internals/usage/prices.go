@@ -0,0 +1,85 @@
+package usage
+
+import (
+	"sort"
+	"strings"
+)
+
+// Price is what a model charges, in US dollars per million tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// Cost returns the price of the given prompt and completion tokens, in US dollars
+func (p Price) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.Input + float64(completionTokens)*p.Output) / 1e6
+}
+
+// DefaultPrices holds the list prices of common models, keyed by model name
+// prefix, or by provider name for providers that cost nothing per token.
+// Prices change often; the config's "prices" table overrides them.
+var DefaultPrices = map[string]Price{
+	"gpt-4o":            {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":       {Input: 0.15, Output: 0.60},
+	"gpt-4.1":           {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini":      {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano":      {Input: 0.10, Output: 0.40},
+	"gpt-5":             {Input: 1.25, Output: 10.00},
+	"gpt-5-mini":        {Input: 0.25, Output: 2.00},
+	"gpt-5-nano":        {Input: 0.05, Output: 0.40},
+	"o4-mini":           {Input: 1.10, Output: 4.40},
+	"gemini-1.5-flash":  {Input: 0.075, Output: 0.30},
+	"gemini-1.5-pro":    {Input: 1.25, Output: 5.00},
+	"gemini-2.0-flash":  {Input: 0.10, Output: 0.40},
+	"gemini-2.5-flash":  {Input: 0.30, Output: 2.50},
+	"gemini-2.5-pro":    {Input: 1.25, Output: 10.00},
+	"deepseek-chat":     {Input: 0.27, Output: 1.10},
+	"deepseek-reasoner": {Input: 0.55, Output: 2.19},
+	"grok-3":            {Input: 3.00, Output: 15.00},
+	"grok-3-mini":       {Input: 0.30, Output: 0.50},
+	"grok-4":            {Input: 3.00, Output: 15.00},
+	"ollama":            {},
+}
+
+// Lookup returns the price of a provider's model. Each table, prices first
+// and then DefaultPrices, is searched for the longest prefix of
+// "<provider>/<model>", then of the model name, then for the provider name.
+func Lookup(prices map[string]Price, provider, model string) (Price, bool) {
+	for _, table := range []map[string]Price{prices, DefaultPrices} {
+		if p, ok := lookupIn(table, provider, model); ok {
+			return p, true
+		}
+	}
+	return Price{}, false
+}
+
+func lookupIn(table map[string]Price, provider, model string) (Price, bool) {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+
+	provider, model = strings.ToLower(provider), strings.ToLower(model)
+	for i, name := range []string{provider + "/" + model, model} {
+		if model == "" {
+			break
+		}
+		for _, key := range keys {
+			// Only keys with a provider are matched against "<provider>/<model>"
+			if i == 0 && !strings.Contains(key, "/") {
+				continue
+			}
+			if strings.HasPrefix(name, strings.ToLower(key)) {
+				return table[key], true
+			}
+		}
+	}
+	for _, key := range keys {
+		if strings.EqualFold(key, provider) {
+			return table[key], true
+		}
+	}
+	return Price{}, false
+}
@@ -0,0 +1,47 @@
+package usage
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	configured := map[string]Price{
+		"gpt-4o":                {Input: 1, Output: 1},
+		"openrouter/openai/gpt": {Input: 2, Output: 2},
+		"My-Model":              {Input: 3, Output: 3},
+		"localai":               {},
+	}
+	tests := []struct {
+		name     string
+		prices   map[string]Price
+		provider string
+		model    string
+		want     Price
+		ok       bool
+	}{
+		{"longest default prefix", nil, "openai", "gpt-4o-mini-2024-07-18", DefaultPrices["gpt-4o-mini"], true},
+		{"shorter default prefix", nil, "openai", "gpt-4o-2024-08-06", DefaultPrices["gpt-4o"], true},
+		{"nano over mini", nil, "openai", "gpt-4.1-nano", DefaultPrices["gpt-4.1-nano"], true},
+		{"config before defaults", configured, "openai", "gpt-4o-mini", Price{Input: 1, Output: 1}, true},
+		{"provider and model prefix", configured, "openrouter", "openai/gpt-4o", Price{Input: 2, Output: 2}, true},
+		{"case-insensitive", configured, "openai-compatible", "my-model-q4", Price{Input: 3, Output: 3}, true},
+		{"provider name", configured, "localai", "anything", Price{}, true},
+		{"free provider", nil, "ollama", "llama3.2", Price{}, true},
+		{"falls back to defaults", configured, "deepseek", "deepseek-chat", DefaultPrices["deepseek-chat"], true},
+		{"unknown", configured, "huggingface", "mistralai/Mistral-7B", Price{}, false},
+		{"provider name is not a prefix", nil, "ollamax", "m", Price{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Lookup(tt.prices, tt.provider, tt.model)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("Lookup(%s, %s) = %+v, %v, want %+v, %v", tt.provider, tt.model, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestPriceCost(t *testing.T) {
+	p := Price{Input: 2.5, Output: 10}
+	if got := p.Cost(1_000_000, 500_000); got != 7.5 {
+		t.Errorf("Cost = %v, want 7.5", got)
+	}
+}